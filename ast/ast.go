@@ -3,6 +3,7 @@ package ast
 import (
 	"bytes"
 	"interpreter/token"
+	"math/big"
 	"strings"
 )
 
@@ -109,9 +110,19 @@ func (es *ExpressionStatement) String() string {
 }
 
 // Integer Type
+type FloatLiteral struct {
+	Token token.Token
+	Value float64
+}
+
+func (fl *FloatLiteral) expressionNode()      {}
+func (fl *FloatLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *FloatLiteral) String() string       { return fl.Token.Literal }
+
 type IntegerLiteral struct {
 	Token token.Token
 	Value int64
+	Big   *big.Int // the value when it does not fit Value, nil otherwise
 }
 
 func (il *IntegerLiteral) expressionNode()      {}
@@ -176,6 +187,15 @@ func (b *Boolean) expressionNode()      {}
 func (b *Boolean) TokenLiteral() string { return b.Token.Literal }
 func (b *Boolean) String() string       { return b.Token.Literal }
 
+//null
+type NullLiteral struct {
+	Token token.Token
+}
+
+func (nl *NullLiteral) expressionNode()      {}
+func (nl *NullLiteral) TokenLiteral() string { return nl.Token.Literal }
+func (nl *NullLiteral) String() string       { return nl.Token.Literal }
+
 // if (<condition>) <consequence> else <alternative>
 type IfExpression struct {
 	Token       token.Token
@@ -199,10 +219,74 @@ func (ie *IfExpression) String() string {
 	return out.String()
 }
 
+// <condition> ? <consequence> : <alternative>
+type ConditionalExpression struct {
+	Token       token.Token // the '?' token
+	Condition   Expression
+	Consequence Expression
+	Alternative Expression
+}
+
+func (ce *ConditionalExpression) expressionNode()      {}
+func (ce *ConditionalExpression) TokenLiteral() string { return ce.Token.Literal }
+func (ce *ConditionalExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("(")
+	out.WriteString(ce.Condition.String())
+	out.WriteString(" ? ")
+	out.WriteString(ce.Consequence.String())
+	out.WriteString(" : ")
+	out.WriteString(ce.Alternative.String())
+	out.WriteString(")")
+	return out.String()
+}
+
+// match (<value>) { <pattern> | <pattern> if <guard> => <body>, _ => <body> }
+type MatchExpression struct {
+	Token  token.Token // the 'match' token
+	Value  Expression
+	Arms   []*MatchArm
+	Rbrace token.Token // the closing '}' token
+}
+
+//one arm of a match expression
+type MatchArm struct {
+	Token    token.Token  // the first token of the arm
+	Patterns []Expression // literals, none for the default arm _
+	Guard    Expression   // nil without an if clause
+	Body     Expression
+}
+
+func (me *MatchExpression) expressionNode()      {}
+func (me *MatchExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MatchExpression) String() string {
+	arms := []string{}
+	for _, arm := range me.Arms {
+		arms = append(arms, arm.String())
+	}
+	return "match (" + me.Value.String() + ") {" + strings.Join(arms, ", ") + "}"
+}
+
+func (ma *MatchArm) String() string {
+	patterns := []string{}
+	for _, pattern := range ma.Patterns {
+		patterns = append(patterns, pattern.String())
+	}
+	if len(patterns) == 0 {
+		patterns = append(patterns, "_")
+	}
+	out := strings.Join(patterns, " | ")
+	if ma.Guard != nil {
+		out += " if " + ma.Guard.String()
+	}
+	return out + " => " + ma.Body.String()
+}
+
 //a block of statements
 type BlockStatement struct {
-	Token      token.Token
+	Token      token.Token // the '{' token
 	Statements []Statement
+	Rbrace     token.Token // the closing '}' token
 }
 
 func (bs *BlockStatement) statementNode()       {}
@@ -244,9 +328,11 @@ func (fl *FunctionLiteral) String() string {
 //add(2 + 2, 3 * 3 * 3)
 //callsFunction(2, 3, fn(x, y) { x + y; });
 type CallExpression struct {
-	Token     token.Token
-	Function  Expression // Identifier or FunctionLiteral
+	Token     token.Token // the '(' token, or '?.' when Optional
+	Function  Expression  // Identifier or FunctionLiteral
 	Arguments []Expression
+	Optional  bool        // f?.(x) is null when f is
+	Rparen    token.Token // the closing ')' token
 }
 
 func (ce *CallExpression) expressionNode()      {}
@@ -258,8 +344,163 @@ func (ce *CallExpression) String() string {
 		args = append(args, a.String())
 	}
 	out.WriteString(ce.Function.String())
-	out.WriteString("(")
+	out.WriteString(optional(ce.Optional) + "(")
 	out.WriteString(strings.Join(args, ", "))
 	out.WriteString(")")
 	return out.String()
 }
+
+//[1, 2 * 2, "three"]
+type ArrayLiteral struct {
+	Token    token.Token // the '[' token
+	Elements []Expression
+	Rbracket token.Token // the closing ']' token
+}
+
+func (al *ArrayLiteral) expressionNode()      {}
+func (al *ArrayLiteral) TokenLiteral() string { return al.Token.Literal }
+func (al *ArrayLiteral) String() string {
+	elements := []string{}
+	for _, el := range al.Elements {
+		elements = append(elements, el.String())
+	}
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+
+///ab+c/i
+type RegexLiteral struct {
+	Token   token.Token
+	Pattern string
+	Flags   string
+}
+
+func (rl *RegexLiteral) expressionNode()      {}
+func (rl *RegexLiteral) TokenLiteral() string { return rl.Token.Literal }
+func (rl *RegexLiteral) String() string       { return rl.Token.Literal }
+
+//{"name": "x", 1: true}
+type HashLiteral struct {
+	Token  token.Token // the '{' token
+	Keys   []Expression
+	Values []Expression // Values[i] is the value of Keys[i]
+	Rbrace token.Token  // the closing '}' token
+}
+
+func (hl *HashLiteral) expressionNode()      {}
+func (hl *HashLiteral) TokenLiteral() string { return hl.Token.Literal }
+func (hl *HashLiteral) String() string {
+	pairs := []string{}
+	for i, key := range hl.Keys {
+		pairs = append(pairs, key.String()+": "+hl.Values[i].String())
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+//text[1:3], list[:n], list[1:]
+type SliceExpression struct {
+	Token    token.Token // the '[' token, or '?.' when Optional
+	Left     Expression
+	Low      Expression  // nil when omitted
+	High     Expression  // nil when omitted
+	Optional bool        // a?.[1:] is null when a is
+	Rbracket token.Token // the closing ']' token
+}
+
+func (se *SliceExpression) expressionNode()      {}
+func (se *SliceExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SliceExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("(")
+	out.WriteString(se.Left.String())
+	out.WriteString(optional(se.Optional) + "[")
+	if se.Low != nil {
+		out.WriteString(se.Low.String())
+	}
+	out.WriteString(":")
+	if se.High != nil {
+		out.WriteString(se.High.String())
+	}
+	out.WriteString("])")
+	return out.String()
+}
+
+//myArray[1]
+//lib["name"]
+type IndexExpression struct {
+	Token    token.Token // the '[' token, or '?.' when Optional
+	Left     Expression
+	Index    Expression
+	Optional bool        // h?.["k"] is null when h is
+	Rbracket token.Token // the closing ']' token
+}
+
+func (ie *IndexExpression) expressionNode()      {}
+func (ie *IndexExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IndexExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("(")
+	out.WriteString(ie.Left.String())
+	out.WriteString(optional(ie.Optional) + "[")
+	out.WriteString(ie.Index.String())
+	out.WriteString("])")
+	return out.String()
+}
+
+//lib.name
+type MemberExpression struct {
+	Token    token.Token // the '.' token, or '?.' when Optional
+	Object   Expression
+	Property *Identifier
+	Optional bool // lib?.name is null when lib is
+}
+
+func (me *MemberExpression) expressionNode()      {}
+func (me *MemberExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MemberExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString(me.Object.String())
+	if me.Optional {
+		out.WriteString("?.")
+	} else {
+		out.WriteString(".")
+	}
+	out.WriteString(me.Property.String())
+	return out.String()
+}
+
+//the "?." of optional calls and indexes
+func optional(ok bool) string {
+	if ok {
+		return "?."
+	}
+	return ""
+}
+
+//import "path/to/lib"
+type ImportExpression struct {
+	Token token.Token
+	Path  *StringLiteral
+}
+
+func (ie *ImportExpression) expressionNode()      {}
+func (ie *ImportExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *ImportExpression) String() string {
+	return ie.TokenLiteral() + " \"" + ie.Path.Value + "\""
+}
+
+//export let xxx = <expression>;
+type ExportStatement struct {
+	Token     token.Token
+	Statement *LetStatement
+}
+
+func (es *ExportStatement) statementNode()       {}
+func (es *ExportStatement) TokenLiteral() string { return es.Token.Literal }
+func (es *ExportStatement) String() string {
+	var out bytes.Buffer
+	out.WriteString(es.TokenLiteral() + " ")
+	if es.Statement != nil {
+		out.WriteString(es.Statement.String())
+	}
+	return out.String()
+}
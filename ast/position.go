@@ -0,0 +1,107 @@
+package ast
+
+import "interpreter/token"
+
+// NodeToken returns the token stored in node: the first token of
+// statements, literals and prefix expressions, the operator or opening
+// delimiter of infix, call, index and member expressions.
+func NodeToken(node Node) token.Token {
+	switch n := node.(type) {
+	case *LetStatement:
+		return n.Token
+	case *ReturnStatement:
+		return n.Token
+	case *ExpressionStatement:
+		return n.Token
+	case *ExportStatement:
+		return n.Token
+	case *BlockStatement:
+		return n.Token
+	case *Identifier:
+		return n.Token
+	case *IntegerLiteral:
+		return n.Token
+	case *FloatLiteral:
+		return n.Token
+	case *StringLiteral:
+		return n.Token
+	case *RegexLiteral:
+		return n.Token
+	case *Boolean:
+		return n.Token
+	case *NullLiteral:
+		return n.Token
+	case *PrefixExpression:
+		return n.Token
+	case *InfixExpression:
+		return n.Token
+	case *IfExpression:
+		return n.Token
+	case *ConditionalExpression:
+		return n.Token
+	case *MatchExpression:
+		return n.Token
+	case *FunctionLiteral:
+		return n.Token
+	case *CallExpression:
+		return n.Token
+	case *IndexExpression:
+		return n.Token
+	case *SliceExpression:
+		return n.Token
+	case *ArrayLiteral:
+		return n.Token
+	case *HashLiteral:
+		return n.Token
+	case *MemberExpression:
+		return n.Token
+	case *ImportExpression:
+		return n.Token
+	}
+	return token.Token{}
+}
+
+// Span returns the first and the last token of node in source order.
+// The parentheses that group an expression are not kept in the AST, so
+// the span of (a + b) ends at b.
+func Span(node Node) (first, last token.Token) {
+	Inspect(node, func(n Node) bool {
+		if n == nil {
+			return false
+		}
+		tokens := []token.Token{NodeToken(n)}
+		switch n := n.(type) {
+		case *BlockStatement:
+			tokens = append(tokens, n.Rbrace)
+		case *MatchExpression:
+			tokens = append(tokens, n.Rbrace)
+		case *HashLiteral:
+			tokens = append(tokens, n.Rbrace)
+		case *CallExpression:
+			tokens = append(tokens, n.Rparen)
+		case *IndexExpression:
+			tokens = append(tokens, n.Rbracket)
+		case *SliceExpression:
+			tokens = append(tokens, n.Rbracket)
+		case *ArrayLiteral:
+			tokens = append(tokens, n.Rbracket)
+		}
+		for _, t := range tokens {
+			if t.Line == 0 {
+				continue
+			}
+			if first.Line == 0 || before(t, first) {
+				first = t
+			}
+			if last.Line == 0 || before(last, t) {
+				last = t
+			}
+		}
+		return true
+	})
+	return first, last
+}
+
+func before(a, b token.Token) bool {
+	return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+}
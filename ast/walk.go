@@ -0,0 +1,163 @@
+package ast
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// A Visitor's Visit method is invoked for each node encountered by Walk.
+// If the result visitor w is not nil, Walk visits each of the children
+// of node with the visitor w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses an AST in depth-first order: It starts by calling
+// v.Visit(node); node must not be nil. If the visitor w returned by
+// v.Visit(node) is not nil, Walk is invoked recursively with visitor
+// w for each of the non-nil children of node, followed by a call of
+// w.Visit(nil).
+//
+// Children the parser failed to build are nil and are skipped, so Walk
+// can be used on programs with parse errors.
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	// Statements
+	case *Program:
+		walkStatements(v, n.Statements)
+	case *LetStatement:
+		walkIdent(v, n.Name)
+		walkExpression(v, n.Value)
+	case *ReturnStatement:
+		walkExpression(v, n.ReturnValue)
+	case *ExpressionStatement:
+		walkExpression(v, n.Expression)
+	case *BlockStatement:
+		walkStatements(v, n.Statements)
+	case *ExportStatement:
+		if n.Statement != nil {
+			Walk(v, n.Statement)
+		}
+
+	// Expressions
+	case *Identifier, *IntegerLiteral, *FloatLiteral, *StringLiteral, *RegexLiteral, *Boolean, *NullLiteral:
+		// nothing to do
+	case *PrefixExpression:
+		walkExpression(v, n.Right)
+	case *InfixExpression:
+		walkExpression(v, n.Left)
+		walkExpression(v, n.Right)
+	case *IfExpression:
+		walkExpression(v, n.Condition)
+		walkBlock(v, n.Consequence)
+		walkBlock(v, n.Alternative)
+	case *ConditionalExpression:
+		walkExpression(v, n.Condition)
+		walkExpression(v, n.Consequence)
+		walkExpression(v, n.Alternative)
+	case *MatchExpression:
+		walkExpression(v, n.Value)
+		for _, arm := range n.Arms {
+			for _, pattern := range arm.Patterns {
+				walkExpression(v, pattern)
+			}
+			walkExpression(v, arm.Guard)
+			walkExpression(v, arm.Body)
+		}
+	case *FunctionLiteral:
+		for _, param := range n.Parameters {
+			walkIdent(v, param)
+		}
+		walkBlock(v, n.Body)
+	case *CallExpression:
+		walkExpression(v, n.Function)
+		for _, arg := range n.Arguments {
+			walkExpression(v, arg)
+		}
+	case *IndexExpression:
+		walkExpression(v, n.Left)
+		walkExpression(v, n.Index)
+	case *SliceExpression:
+		walkExpression(v, n.Left)
+		walkExpression(v, n.Low)
+		walkExpression(v, n.High)
+	case *ArrayLiteral:
+		for _, el := range n.Elements {
+			walkExpression(v, el)
+		}
+	case *HashLiteral:
+		for i, key := range n.Keys {
+			walkExpression(v, key)
+			walkExpression(v, n.Values[i])
+		}
+	case *MemberExpression:
+		walkExpression(v, n.Object)
+		walkIdent(v, n.Property)
+	case *ImportExpression:
+		if n.Path != nil {
+			Walk(v, n.Path)
+		}
+
+	default:
+		panic(fmt.Sprintf("ast.Walk: unexpected node type %T", n))
+	}
+
+	v.Visit(nil)
+}
+
+func walkStatements(v Visitor, list []Statement) {
+	for _, stmt := range list {
+		if !isNil(stmt) {
+			Walk(v, stmt)
+		}
+	}
+}
+
+func walkExpression(v Visitor, exp Expression) {
+	if !isNil(exp) {
+		Walk(v, exp)
+	}
+}
+
+func walkIdent(v Visitor, ident *Identifier) {
+	if ident != nil {
+		Walk(v, ident)
+	}
+}
+
+func walkBlock(v Visitor, block *BlockStatement) {
+	if block != nil {
+		Walk(v, block)
+	}
+}
+
+// isNil reports whether n is nil or a nil pointer, which the parser
+// leaves behind for statements and expressions it could not parse.
+func isNil(n Node) bool {
+	if n == nil {
+		return true
+	}
+	rv := reflect.ValueOf(n)
+	return rv.Kind() == reflect.Ptr && rv.IsNil()
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses an AST in depth-first order: It starts by calling
+// f(node); node must not be nil. If f returns true, Inspect invokes f
+// recursively for each of the non-nil children of node, followed by a
+// call of f(nil).
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
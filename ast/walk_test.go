@@ -0,0 +1,163 @@
+package ast_test
+
+import (
+	"fmt"
+	"interpreter/ast"
+	"interpreter/lexer"
+	"interpreter/parser"
+	"strings"
+	"testing"
+)
+
+// every node type the parser can produce
+const walkInput = `
+let lib = import "lib";
+export let answer = 42;
+let add = fn(x, y) { return x + y; };
+if (!true) { add(1.5, -2) } else { lib.f(lib["g"]) };
+/a+/i.match("aa");
+["done", lib[1:], {"ok": true}, null];
+answer > 40 ? match (answer) { 42 | 43 if add => "yes", _ => "no" } : "no";
+`
+
+func parse(t *testing.T, input string) *ast.Program {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser errors: %v", p.Errors())
+	}
+	return program
+}
+
+func TestInspectCoversAllNodes(t *testing.T) {
+	program := parse(t, walkInput)
+
+	seen := map[string]bool{}
+	ast.Inspect(program, func(n ast.Node) bool {
+		if n != nil {
+			seen[fmt.Sprintf("%T", n)] = true
+		}
+		return true
+	})
+
+	expected := []ast.Node{
+		&ast.Program{},
+		&ast.LetStatement{},
+		&ast.ReturnStatement{},
+		&ast.ExpressionStatement{},
+		&ast.BlockStatement{},
+		&ast.ExportStatement{},
+		&ast.Identifier{},
+		&ast.IntegerLiteral{},
+		&ast.FloatLiteral{},
+		&ast.StringLiteral{},
+		&ast.RegexLiteral{},
+		&ast.Boolean{},
+		&ast.NullLiteral{},
+		&ast.PrefixExpression{},
+		&ast.InfixExpression{},
+		&ast.IfExpression{},
+		&ast.ConditionalExpression{},
+		&ast.MatchExpression{},
+		&ast.FunctionLiteral{},
+		&ast.CallExpression{},
+		&ast.IndexExpression{},
+		&ast.SliceExpression{},
+		&ast.ArrayLiteral{},
+		&ast.HashLiteral{},
+		&ast.MemberExpression{},
+		&ast.ImportExpression{},
+	}
+	for _, n := range expected {
+		if name := fmt.Sprintf("%T", n); !seen[name] {
+			t.Errorf("node type %s not visited", name)
+		}
+	}
+}
+
+func TestInspectOrder(t *testing.T) {
+	program := parse(t, `if (a) { f(b, c) } else { g }; fn(x, y) { x }; h ? i : match (j) { 1 if k => l, _ => m };`)
+
+	var visited []string
+	ast.Inspect(program, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Identifier); ok {
+			visited = append(visited, ident.Value)
+		}
+		return true
+	})
+
+	// condition, consequence with call function and arguments,
+	// alternative, then function parameters and body, then the parts of
+	// conditionals and of match arms in source order
+	expected := "a f b c g x y x h i j k l m"
+	if got := strings.Join(visited, " "); got != expected {
+		t.Errorf("wrong visiting order. expected=%q, got=%q", expected, got)
+	}
+}
+
+func TestInspectPrune(t *testing.T) {
+	program := parse(t, `let f = fn(a) { b }; c;`)
+
+	var visited []string
+	ast.Inspect(program, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Identifier); ok {
+			visited = append(visited, ident.Value)
+		}
+		_, isFunc := n.(*ast.FunctionLiteral)
+		return !isFunc
+	})
+
+	expected := "f c"
+	if got := strings.Join(visited, " "); got != expected {
+		t.Errorf("wrong visited identifiers. expected=%q, got=%q", expected, got)
+	}
+}
+
+type depthVisitor struct {
+	depth    int
+	maxDepth *int
+	opened   *int
+	closed   *int
+}
+
+func (v depthVisitor) Visit(n ast.Node) ast.Visitor {
+	if n == nil {
+		*v.closed++
+		return nil
+	}
+	*v.opened++
+	if v.depth > *v.maxDepth {
+		*v.maxDepth = v.depth
+	}
+	return depthVisitor{v.depth + 1, v.maxDepth, v.opened, v.closed}
+}
+
+func TestWalk(t *testing.T) {
+	program := parse(t, `-(1 + 2);`)
+
+	var maxDepth, opened, closed int
+	ast.Walk(depthVisitor{0, &maxDepth, &opened, &closed}, program)
+
+	// Program > ExpressionStatement > PrefixExpression > InfixExpression > IntegerLiteral
+	if maxDepth != 4 {
+		t.Errorf("wrong max depth. expected=%d, got=%d", 4, maxDepth)
+	}
+	if opened != 6 || closed != opened {
+		t.Errorf("unbalanced Visit calls. opened=%d, closed=%d", opened, closed)
+	}
+}
+
+func TestWalkSkipsMissingNodes(t *testing.T) {
+	// parse errors leave nil statements and expressions behind
+	p := parser.New(lexer.New(`let = 5; return; if (x) { y }`))
+	program := p.ParseProgram()
+
+	count := 0
+	ast.Inspect(program, func(n ast.Node) bool {
+		count++
+		return true
+	})
+	if count == 0 {
+		t.Errorf("nothing visited")
+	}
+}
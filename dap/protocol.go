@@ -0,0 +1,133 @@
+package dap
+
+import "encoding/json"
+
+// The subset of the Debug Adapter Protocol the server speaks.
+// https://microsoft.github.io/debug-adapter-protocol/specification
+
+// message holds any incoming or outgoing message.
+type message struct {
+	Seq        int             `json:"seq"`
+	Type       string          `json:"type"`
+	Command    string          `json:"command,omitempty"`
+	Arguments  json.RawMessage `json:"arguments,omitempty"`
+	RequestSeq int             `json:"request_seq,omitempty"`
+	Success    *bool           `json:"success,omitempty"`
+	Message    string          `json:"message,omitempty"`
+	Event      string          `json:"event,omitempty"`
+	Body       json.RawMessage `json:"body,omitempty"`
+}
+
+type Capabilities struct {
+	SupportsConfigurationDoneRequest bool `json:"supportsConfigurationDoneRequest"`
+	SupportsEvaluateForHovers        bool `json:"supportsEvaluateForHovers"`
+}
+
+type LaunchArguments struct {
+	Program     string `json:"program"`
+	StopOnEntry bool   `json:"stopOnEntry"`
+}
+
+type Source struct {
+	Name string `json:"name,omitempty"`
+	Path string `json:"path,omitempty"`
+}
+
+type SourceBreakpoint struct {
+	Line int `json:"line"`
+}
+
+type SetBreakpointsArguments struct {
+	Source      Source             `json:"source"`
+	Breakpoints []SourceBreakpoint `json:"breakpoints"`
+}
+
+type Breakpoint struct {
+	Verified bool `json:"verified"`
+	Line     int  `json:"line"`
+}
+
+type SetBreakpointsResponseBody struct {
+	Breakpoints []Breakpoint `json:"breakpoints"`
+}
+
+type Thread struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type ThreadsResponseBody struct {
+	Threads []Thread `json:"threads"`
+}
+
+type StackFrame struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Source Source `json:"source"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+type StackTraceResponseBody struct {
+	StackFrames []StackFrame `json:"stackFrames"`
+	TotalFrames int          `json:"totalFrames"`
+}
+
+type ScopesArguments struct {
+	FrameID int `json:"frameId"`
+}
+
+type Scope struct {
+	Name               string `json:"name"`
+	VariablesReference int    `json:"variablesReference"`
+	Expensive          bool   `json:"expensive"`
+}
+
+type ScopesResponseBody struct {
+	Scopes []Scope `json:"scopes"`
+}
+
+type VariablesArguments struct {
+	VariablesReference int `json:"variablesReference"`
+}
+
+type Variable struct {
+	Name               string `json:"name"`
+	Value              string `json:"value"`
+	Type               string `json:"type,omitempty"`
+	VariablesReference int    `json:"variablesReference"`
+}
+
+type VariablesResponseBody struct {
+	Variables []Variable `json:"variables"`
+}
+
+type EvaluateArguments struct {
+	Expression string `json:"expression"`
+	FrameID    int    `json:"frameId"`
+}
+
+type EvaluateResponseBody struct {
+	Result             string `json:"result"`
+	Type               string `json:"type,omitempty"`
+	VariablesReference int    `json:"variablesReference"`
+}
+
+type ContinueResponseBody struct {
+	AllThreadsContinued bool `json:"allThreadsContinued"`
+}
+
+type StoppedEventBody struct {
+	Reason            string `json:"reason"`
+	ThreadID          int    `json:"threadId"`
+	AllThreadsStopped bool   `json:"allThreadsStopped"`
+}
+
+type OutputEventBody struct {
+	Category string `json:"category"`
+	Output   string `json:"output"`
+}
+
+type ExitedEventBody struct {
+	ExitCode int `json:"exitCode"`
+}
@@ -0,0 +1,355 @@
+// Package dap implements a Debug Adapter Protocol server on top of the
+// debugger package, so that scripts can be debugged from editors.
+package dap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"interpreter/debugger"
+	"interpreter/framing"
+	"interpreter/object"
+	"io"
+	"path/filepath"
+	"sync"
+)
+
+// scripts run on a single thread
+const threadID = 1
+
+type Server struct {
+	r *framing.Reader
+	w *framing.Writer
+
+	mu  sync.Mutex // guards seq, paused and refs
+	seq int
+
+	rt *object.Runtime
+	d  *debugger.Debugger
+
+	program     string
+	stopOnEntry bool
+	launched    bool
+	configured  bool
+	running     bool
+
+	// while paused, the script goroutine waits on resume
+	paused bool
+	resume chan struct{}
+	refs   []interface{} // values of variablesReference - 1
+	done   chan struct{}
+
+	// run after the response to the current request is sent, so that
+	// a resumed script cannot send events ahead of it
+	then func()
+}
+
+// NewServer creates a server debugging scripts evaluated with rt.
+func NewServer(rt *object.Runtime) *Server {
+	s := &Server{
+		rt:     rt,
+		resume: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	s.d = debugger.New(rt)
+	s.d.OnPause = s.pause
+	rt.Stdout = outputWriter{s}
+	return s
+}
+
+// Serve handles the requests read from r until the client disconnects.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.r = framing.NewReader(r)
+	s.w = framing.NewWriter(w)
+	for {
+		body, err := s.r.Read()
+		if err == io.EOF {
+			s.stop()
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		req := &message{}
+		if err := json.Unmarshal(body, req); err != nil {
+			return err
+		}
+		if req.Type != "request" {
+			continue
+		}
+		s.then = nil
+		result, err := s.handle(req)
+		if err := s.respond(req, result, err); err != nil {
+			return err
+		}
+		if s.then != nil {
+			s.then()
+		}
+		if req.Command == "disconnect" {
+			return nil
+		}
+	}
+}
+
+func (s *Server) send(msg *message) error {
+	s.mu.Lock()
+	s.seq++
+	msg.Seq = s.seq
+	s.mu.Unlock()
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return s.w.Write(body)
+}
+
+func (s *Server) respond(req *message, result interface{}, rerr error) error {
+	success := rerr == nil
+	resp := &message{Type: "response", RequestSeq: req.Seq, Command: req.Command, Success: &success}
+	if rerr != nil {
+		resp.Message = rerr.Error()
+	} else if result != nil {
+		body, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		resp.Body = body
+	}
+	return s.send(resp)
+}
+
+func (s *Server) event(name string, body interface{}) {
+	msg := &message{Type: "event", Event: name}
+	if body != nil {
+		msg.Body, _ = json.Marshal(body)
+	}
+	s.send(msg)
+}
+
+// outputWriter turns the output of scripts into output events.
+type outputWriter struct{ s *Server }
+
+func (w outputWriter) Write(p []byte) (int, error) {
+	w.s.event("output", OutputEventBody{Category: "stdout", Output: string(p)})
+	return len(p), nil
+}
+
+var errNotPaused = errors.New("the script is not paused")
+
+func (s *Server) handle(req *message) (interface{}, error) {
+	switch req.Command {
+	case "initialize":
+		s.then = func() { s.event("initialized", nil) }
+		return Capabilities{SupportsConfigurationDoneRequest: true, SupportsEvaluateForHovers: true}, nil
+	case "launch":
+		var args LaunchArguments
+		if err := json.Unmarshal(req.Arguments, &args); err != nil {
+			return nil, err
+		}
+		if args.Program == "" {
+			return nil, errors.New("launch: no program given")
+		}
+		s.program, s.stopOnEntry, s.launched = args.Program, args.StopOnEntry, true
+		s.then = s.start
+		return nil, nil
+	case "setBreakpoints":
+		return s.setBreakpoints(req)
+	case "configurationDone":
+		s.configured = true
+		s.then = s.start
+		return nil, nil
+	case "threads":
+		return ThreadsResponseBody{Threads: []Thread{{ID: threadID, Name: "main"}}}, nil
+	case "continue":
+		return ContinueResponseBody{AllThreadsContinued: true}, s.continueWith(s.d.Continue)
+	case "next":
+		return nil, s.continueWith(s.d.StepOver)
+	case "stepIn":
+		return nil, s.continueWith(s.d.StepIn)
+	case "stepOut":
+		return nil, s.continueWith(s.d.StepOut)
+	case "stackTrace":
+		return s.stackTrace()
+	case "scopes":
+		return s.scopes(req)
+	case "variables":
+		return s.variables(req)
+	case "evaluate":
+		return s.evaluate(req)
+	case "disconnect":
+		s.stop()
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unsupported request %q", req.Command)
+}
+
+// start runs the script once it is launched and configured.
+func (s *Server) start() {
+	if !s.launched || !s.configured || s.running {
+		return
+	}
+	s.running = true
+	go func() {
+		defer close(s.done)
+		result, aborted := s.d.Run(s.program, s.stopOnEntry)
+		exitCode := 0
+		if !aborted && result != nil && result.Type() == object.ERROR_OBJ {
+			s.event("output", OutputEventBody{Category: "stderr", Output: result.Inspect() + "\n"})
+			exitCode = 1
+		}
+		s.event("exited", ExitedEventBody{ExitCode: exitCode})
+		s.event("terminated", nil)
+	}()
+}
+
+// stop aborts the script, if it runs, and waits for it to end.
+func (s *Server) stop() {
+	if !s.running {
+		return
+	}
+	s.d.Stop()
+	if s.continueWith(func() {}) == nil {
+		s.then()
+		s.then = nil
+	}
+	<-s.done
+}
+
+// pause runs on the script goroutine each time the debugger stops.
+func (s *Server) pause(reason string) {
+	s.mu.Lock()
+	s.paused = true
+	s.refs = nil
+	s.mu.Unlock()
+	s.event("stopped", StoppedEventBody{Reason: reason, ThreadID: threadID, AllThreadsStopped: true})
+	<-s.resume
+}
+
+// continueWith resumes the paused script once the response is sent,
+// after calling step, which selects how far it runs.
+func (s *Server) continueWith(step func()) error {
+	s.mu.Lock()
+	paused := s.paused
+	s.paused = false
+	s.mu.Unlock()
+	if !paused {
+		return errNotPaused
+	}
+	step()
+	s.then = func() { s.resume <- struct{}{} }
+	return nil
+}
+
+func (s *Server) isPaused() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.paused
+}
+
+func (s *Server) setBreakpoints(req *message) (interface{}, error) {
+	var args SetBreakpointsArguments
+	if err := json.Unmarshal(req.Arguments, &args); err != nil {
+		return nil, err
+	}
+	lines := []int{}
+	body := SetBreakpointsResponseBody{Breakpoints: []Breakpoint{}}
+	for _, bp := range args.Breakpoints {
+		lines = append(lines, bp.Line)
+		body.Breakpoints = append(body.Breakpoints, Breakpoint{Verified: true, Line: bp.Line})
+	}
+	s.d.SetBreakpoints(args.Source.Path, lines)
+	return body, nil
+}
+
+// Frame IDs are the positions in the stack, innermost first, plus one.
+func (s *Server) stackTrace() (interface{}, error) {
+	if !s.isPaused() {
+		return nil, errNotPaused
+	}
+	body := StackTraceResponseBody{StackFrames: []StackFrame{}}
+	for i, frame := range s.d.Stack() {
+		body.StackFrames = append(body.StackFrames, StackFrame{
+			ID:     i + 1,
+			Name:   frame.Name,
+			Source: Source{Name: filepath.Base(frame.File), Path: frame.File},
+			Line:   frame.Line,
+			Column: 1,
+		})
+	}
+	body.TotalFrames = len(body.StackFrames)
+	return body, nil
+}
+
+func (s *Server) frame(id int) (*debugger.Frame, error) {
+	stack := s.d.Stack()
+	if id < 1 || id > len(stack) {
+		return nil, fmt.Errorf("no frame %d", id)
+	}
+	return stack[id-1], nil
+}
+
+// scopes lists the environments of a frame: its locals, those of the
+// functions it is nested in and the globals.
+func (s *Server) scopes(req *message) (interface{}, error) {
+	var args ScopesArguments
+	if err := json.Unmarshal(req.Arguments, &args); err != nil {
+		return nil, err
+	}
+	if !s.isPaused() {
+		return nil, errNotPaused
+	}
+	frame, err := s.frame(args.FrameID)
+	if err != nil {
+		return nil, err
+	}
+	body := ScopesResponseBody{Scopes: []Scope{}}
+	for env := frame.Env; env != nil; env = env.Outer() {
+		name := "Closure"
+		switch {
+		case env.Outer() == nil:
+			name = "Globals"
+		case env == frame.Env:
+			name = "Locals"
+		}
+		body.Scopes = append(body.Scopes, Scope{Name: name, VariablesReference: s.reference(env)})
+	}
+	return body, nil
+}
+
+func (s *Server) variables(req *message) (interface{}, error) {
+	var args VariablesArguments
+	if err := json.Unmarshal(req.Arguments, &args); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	if !s.paused || args.VariablesReference < 1 || args.VariablesReference > len(s.refs) {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("invalid variablesReference %d", args.VariablesReference)
+	}
+	value := s.refs[args.VariablesReference-1]
+	s.mu.Unlock()
+	return VariablesResponseBody{Variables: s.children(value)}, nil
+}
+
+func (s *Server) evaluate(req *message) (interface{}, error) {
+	var args EvaluateArguments
+	if err := json.Unmarshal(req.Arguments, &args); err != nil {
+		return nil, err
+	}
+	if !s.isPaused() {
+		return nil, errNotPaused
+	}
+	frame := 0
+	if args.FrameID > 0 {
+		frame = args.FrameID - 1
+	}
+	result := s.d.Evaluate(args.Expression, frame)
+	if result == nil {
+		return EvaluateResponseBody{Result: ""}, nil
+	}
+	if err, ok := result.(*object.Error); ok {
+		return nil, errors.New(err.Message)
+	}
+	v := s.variable("", result)
+	return EvaluateResponseBody{Result: v.Value, Type: v.Type, VariablesReference: v.VariablesReference}, nil
+}
@@ -0,0 +1,329 @@
+package dap
+
+import (
+	"encoding/json"
+	"fmt"
+	"interpreter/framing"
+	"interpreter/object"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const script = `let make = fn(n) {
+	let k = n * 2;
+	fn(x) { x + k }
+};
+let add = make(3);
+let r = add(4);
+echo(r);
+`
+
+// client drives a Server in-process over pipes, the way an editor does
+// over stdio.
+type client struct {
+	t      *testing.T
+	r      *framing.Reader
+	w      *framing.Writer
+	seq    int
+	events []*message
+	done   chan error
+}
+
+func newClient(t *testing.T) *client {
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+	c := &client{t: t, r: framing.NewReader(clientIn), w: framing.NewWriter(clientOut), done: make(chan error, 1)}
+	go func() {
+		err := NewServer(object.NewRuntime()).Serve(serverIn, serverOut)
+		serverOut.Close()
+		c.done <- err
+	}()
+	return c
+}
+
+func (c *client) read() *message {
+	c.t.Helper()
+	body, err := c.r.Read()
+	if err != nil {
+		c.t.Fatalf("read: %s", err)
+	}
+	msg := &message{}
+	if err := json.Unmarshal(body, msg); err != nil {
+		c.t.Fatal(err)
+	}
+	return msg
+}
+
+// call sends a request and decodes the body of its response into result.
+func (c *client) call(command string, args interface{}, result interface{}) {
+	c.t.Helper()
+	if err := c.request(command, args, result); err != nil {
+		c.t.Fatalf("%s failed: %s", command, err)
+	}
+}
+
+func (c *client) request(command string, args interface{}, result interface{}) error {
+	c.t.Helper()
+	c.seq++
+	raw, err := json.Marshal(args)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	body, _ := json.Marshal(&message{Seq: c.seq, Type: "request", Command: command, Arguments: raw})
+	if err := c.w.Write(body); err != nil {
+		c.t.Fatalf("write %s: %s", command, err)
+	}
+	for {
+		msg := c.read()
+		if msg.Type == "event" {
+			c.events = append(c.events, msg)
+			continue
+		}
+		if msg.RequestSeq != c.seq || msg.Command != command {
+			c.t.Fatalf("unexpected response %+v", msg)
+		}
+		if !*msg.Success {
+			return fmt.Errorf("%s", msg.Message)
+		}
+		if result != nil {
+			if err := json.Unmarshal(msg.Body, result); err != nil {
+				c.t.Fatalf("decode body of %s: %s", command, err)
+			}
+		}
+		return nil
+	}
+}
+
+// event waits for the next event, which must be called name.
+func (c *client) event(name string, body interface{}) {
+	c.t.Helper()
+	var msg *message
+	if len(c.events) > 0 {
+		msg, c.events = c.events[0], c.events[1:]
+	} else {
+		msg = c.read()
+	}
+	if msg.Type != "event" || msg.Event != name {
+		c.t.Fatalf("expected %s event. got=%+v", name, msg)
+	}
+	if body != nil {
+		if err := json.Unmarshal(msg.Body, body); err != nil {
+			c.t.Fatal(err)
+		}
+	}
+}
+
+func (c *client) stopped(reason string, line int) {
+	c.t.Helper()
+	var stopped StoppedEventBody
+	c.event("stopped", &stopped)
+	if stopped.Reason != reason || stopped.ThreadID != threadID {
+		c.t.Fatalf("wrong stopped event. got=%+v", stopped)
+	}
+	var trace StackTraceResponseBody
+	c.call("stackTrace", map[string]int{"threadId": threadID}, &trace)
+	if trace.StackFrames[0].Line != line {
+		c.t.Fatalf("stopped at wrong line. expected=%d, got=%d", line, trace.StackFrames[0].Line)
+	}
+}
+
+func (c *client) variables(ref int) string {
+	c.t.Helper()
+	var body VariablesResponseBody
+	c.call("variables", VariablesArguments{VariablesReference: ref}, &body)
+	var vars []string
+	for _, v := range body.Variables {
+		vars = append(vars, v.Name+"="+v.Value)
+	}
+	return strings.Join(vars, " ")
+}
+
+func writeScript(t *testing.T, src string) string {
+	path := filepath.Join(t.TempDir(), "main.tl")
+	if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func launch(t *testing.T, src string, breakpoints ...int) *client {
+	path := writeScript(t, src)
+	c := newClient(t)
+	var caps Capabilities
+	c.call("initialize", map[string]string{"adapterID": "interpreter"}, &caps)
+	if !caps.SupportsConfigurationDoneRequest {
+		t.Fatalf("wrong capabilities. got=%+v", caps)
+	}
+	c.event("initialized", nil)
+	c.call("launch", LaunchArguments{Program: path}, nil)
+	args := SetBreakpointsArguments{Source: Source{Path: path}}
+	for _, line := range breakpoints {
+		args.Breakpoints = append(args.Breakpoints, SourceBreakpoint{Line: line})
+	}
+	var bps SetBreakpointsResponseBody
+	c.call("setBreakpoints", args, &bps)
+	if len(bps.Breakpoints) != len(breakpoints) {
+		t.Fatalf("wrong breakpoints. got=%+v", bps.Breakpoints)
+	}
+	c.call("configurationDone", nil, nil)
+	return c
+}
+
+func (c *client) disconnect() {
+	c.t.Helper()
+	c.call("disconnect", nil, nil)
+	if err := <-c.done; err != nil {
+		c.t.Errorf("server returned error: %s", err)
+	}
+}
+
+func TestSession(t *testing.T) {
+	c := launch(t, script, 6)
+	c.stopped("breakpoint", 6)
+
+	var threads ThreadsResponseBody
+	c.call("threads", nil, &threads)
+	if len(threads.Threads) != 1 || threads.Threads[0].Name != "main" {
+		t.Fatalf("wrong threads. got=%+v", threads)
+	}
+
+	var scopes ScopesResponseBody
+	c.call("scopes", ScopesArguments{FrameID: 1}, &scopes)
+	if len(scopes.Scopes) != 1 || scopes.Scopes[0].Name != "Globals" {
+		t.Fatalf("wrong scopes. got=%+v", scopes)
+	}
+	var globals VariablesResponseBody
+	c.call("variables", VariablesArguments{VariablesReference: scopes.Scopes[0].VariablesReference}, &globals)
+	if len(globals.Variables) != 2 {
+		t.Fatalf("wrong globals. got=%+v", globals)
+	}
+	add := globals.Variables[0]
+	if add.Name != "add" || add.Value != "fn(x)" || add.VariablesReference == 0 {
+		t.Fatalf("wrong variable. got=%+v", add)
+	}
+	// the closure captured the environment of the call to make
+	if captured := c.variables(add.VariablesReference); captured != "k=6 n=3" {
+		t.Errorf("wrong captured environment. got=%q", captured)
+	}
+
+	c.call("stepIn", map[string]int{"threadId": threadID}, nil)
+	c.stopped("step", 3)
+	var trace StackTraceResponseBody
+	c.call("stackTrace", map[string]int{"threadId": threadID}, &trace)
+	if len(trace.StackFrames) != 2 || trace.StackFrames[0].Name != "add" || trace.StackFrames[1].Line != 6 {
+		t.Fatalf("wrong stack. got=%+v", trace.StackFrames)
+	}
+	c.call("scopes", ScopesArguments{FrameID: 1}, &scopes)
+	var names []string
+	for _, scope := range scopes.Scopes {
+		names = append(names, scope.Name)
+	}
+	if strings.Join(names, " ") != "Locals Closure Globals" {
+		t.Fatalf("wrong scopes. got=%v", names)
+	}
+	if locals := c.variables(scopes.Scopes[0].VariablesReference); locals != "x=4" {
+		t.Errorf("wrong locals. got=%q", locals)
+	}
+
+	c.call("stepOut", map[string]int{"threadId": threadID}, nil)
+	c.stopped("step", 7)
+	var eval EvaluateResponseBody
+	c.call("evaluate", EvaluateArguments{Expression: "r * 2", FrameID: 1}, &eval)
+	if eval.Result != "20" || eval.Type != "INTEGER" {
+		t.Errorf("wrong evaluation. got=%+v", eval)
+	}
+	if err := c.request("evaluate", EvaluateArguments{Expression: "missing", FrameID: 1}, nil); err == nil || err.Error() != "identifier not found: missing" {
+		t.Errorf("wrong evaluation error. got=%v", err)
+	}
+
+	var cont ContinueResponseBody
+	c.call("continue", map[string]int{"threadId": threadID}, &cont)
+	var output OutputEventBody
+	c.event("output", &output)
+	if output.Category != "stdout" || output.Output != "10\n" {
+		t.Errorf("wrong output. got=%+v", output)
+	}
+	var exited ExitedEventBody
+	c.event("exited", &exited)
+	if exited.ExitCode != 0 {
+		t.Errorf("wrong exit code. got=%d", exited.ExitCode)
+	}
+	c.event("terminated", nil)
+	c.disconnect()
+}
+
+func TestScriptError(t *testing.T) {
+	c := launch(t, "let x = 1;\nx + true;\n")
+	var output OutputEventBody
+	c.event("output", &output)
+	if output.Category != "stderr" || output.Output != "ERROR: type mismatch: INTEGER + BOOLEAN\n" {
+		t.Errorf("wrong output. got=%+v", output)
+	}
+	var exited ExitedEventBody
+	c.event("exited", &exited)
+	if exited.ExitCode != 1 {
+		t.Errorf("wrong exit code. got=%d", exited.ExitCode)
+	}
+	c.event("terminated", nil)
+	c.disconnect()
+}
+
+func TestDisconnectWhilePaused(t *testing.T) {
+	c := launch(t, script, 5)
+	c.stopped("breakpoint", 5)
+	c.call("next", map[string]int{"threadId": threadID}, nil)
+	c.stopped("step", 6)
+	c.disconnect()
+}
+
+func TestArrayVariables(t *testing.T) {
+	c := launch(t, "let xs = [1, [\"a\", fn(x) { x }]];\nlet y = 2;\n", 2)
+	c.stopped("breakpoint", 2)
+
+	var scopes ScopesResponseBody
+	c.call("scopes", ScopesArguments{FrameID: 1}, &scopes)
+	var globals VariablesResponseBody
+	c.call("variables", VariablesArguments{VariablesReference: scopes.Scopes[0].VariablesReference}, &globals)
+	xs := globals.Variables[0]
+	if xs.Name != "xs" || xs.Value != `[1, ["a", fn(x)]]` || xs.Type != "ARRAY" {
+		t.Fatalf("wrong variable. got=%+v", xs)
+	}
+	var elements VariablesResponseBody
+	c.call("variables", VariablesArguments{VariablesReference: xs.VariablesReference}, &elements)
+	if len(elements.Variables) != 2 || elements.Variables[0].VariablesReference != 0 {
+		t.Fatalf("wrong elements. got=%+v", elements)
+	}
+	if nested := c.variables(elements.Variables[1].VariablesReference); nested != `0="a" 1=fn(x)` {
+		t.Errorf("wrong nested elements. got=%q", nested)
+	}
+
+	c.call("continue", map[string]int{"threadId": threadID}, nil)
+	c.event("exited", nil)
+	c.event("terminated", nil)
+	c.disconnect()
+}
+
+func TestHashVariables(t *testing.T) {
+	c := launch(t, "let h = {\"name\": \"x\", 2: [true]};\nlet y = 2;\n", 2)
+	c.stopped("breakpoint", 2)
+
+	var scopes ScopesResponseBody
+	c.call("scopes", ScopesArguments{FrameID: 1}, &scopes)
+	var globals VariablesResponseBody
+	c.call("variables", VariablesArguments{VariablesReference: scopes.Scopes[0].VariablesReference}, &globals)
+	h := globals.Variables[0]
+	if h.Name != "h" || h.Value != `{"name": "x", 2: [true]}` || h.Type != "HASH" {
+		t.Fatalf("wrong variable. got=%+v", h)
+	}
+	if pairs := c.variables(h.VariablesReference); pairs != `"name"="x" 2=[true]` {
+		t.Errorf("wrong pairs. got=%q", pairs)
+	}
+
+	c.call("continue", map[string]int{"threadId": threadID}, nil)
+	c.event("exited", nil)
+	c.event("terminated", nil)
+	c.disconnect()
+}
@@ -0,0 +1,86 @@
+package dap
+
+import (
+	"interpreter/debugger"
+	"interpreter/object"
+	"sort"
+	"strconv"
+)
+
+// reference registers a value whose children the client can ask for.
+// References are only valid until the script resumes.
+func (s *Server) reference(value interface{}) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refs = append(s.refs, value)
+	return len(s.refs)
+}
+
+// variable describes a value, with a reference to its children if it
+// has any.
+func (s *Server) variable(name string, obj object.Object) Variable {
+	v := Variable{Name: name, Value: debugger.Summary(obj), Type: string(obj.Type())}
+	if str, ok := obj.(*object.String); ok {
+		v.Value = object.InspectElement(str)
+	}
+	switch obj := obj.(type) {
+	case *object.Function:
+		if obj.Env != nil {
+			v.VariablesReference = s.reference(obj)
+		}
+	case *object.Module:
+		if len(obj.Exports) > 0 {
+			v.VariablesReference = s.reference(obj)
+		}
+	case *object.Array:
+		if len(obj.Elements) > 0 {
+			v.VariablesReference = s.reference(obj)
+		}
+	case *object.Hash:
+		if len(obj.Keys) > 0 {
+			v.VariablesReference = s.reference(obj)
+		}
+	}
+	return v
+}
+
+// children lists the variables inside an environment or a value:
+// the bindings captured by a closure, the exports of a module, the
+// elements of an array, the pairs of a hash.
+func (s *Server) children(value interface{}) []Variable {
+	vars := []Variable{}
+	switch value := value.(type) {
+	case *object.Environment:
+		for _, name := range value.Names() {
+			obj, _ := value.Get(name)
+			vars = append(vars, s.variable(name, obj))
+		}
+	case *object.Function:
+		// the environment the function was defined in, without the
+		// globals every function shares
+		for env := value.Env; env != nil && env.Outer() != nil; env = env.Outer() {
+			for _, name := range env.Names() {
+				obj, _ := env.Get(name)
+				vars = append(vars, s.variable(name, obj))
+			}
+		}
+	case *object.Array:
+		for i, el := range value.Elements {
+			vars = append(vars, s.variable(strconv.Itoa(i), el))
+		}
+	case *object.Hash:
+		for _, pair := range value.Ordered() {
+			vars = append(vars, s.variable(object.InspectElement(pair.Key), pair.Value))
+		}
+	case *object.Module:
+		names := make([]string, 0, len(value.Exports))
+		for name := range value.Exports {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			vars = append(vars, s.variable(name, value.Exports[name]))
+		}
+	}
+	return vars
+}
@@ -0,0 +1,210 @@
+package debugger
+
+import (
+	"bufio"
+	"fmt"
+	"interpreter/object"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const PROMPT = "(debug) "
+
+const HELP = `commands:
+  break [file:]line   set a breakpoint (b)
+  clear [file:]line   remove a breakpoint
+  continue            run to the next breakpoint (c)
+  step                step to the next statement, entering calls (s)
+  next                step over calls to the next statement (n)
+  out                 run until the current function returns (o)
+  locals              print the bindings visible in the frame (l)
+  print expr          evaluate an expression in the frame (p)
+  stack               print the call stack (bt)
+  frame n             select frame n of the stack (f)
+  quit                stop the script (q)
+`
+
+// cli drives a Debugger from commands read line by line.
+type cli struct {
+	d       *Debugger
+	scanner *bufio.Scanner
+	out     io.Writer
+	main    string
+	frame   int
+	sources map[string][]string
+}
+
+// Start debugs the script at path, reading commands from in. The script
+// is paused before its first statement. It returns 1 if the script
+// raised an error.
+func Start(path string, rt *object.Runtime, in io.Reader, out io.Writer) int {
+	c := &cli{
+		d:       New(rt),
+		scanner: bufio.NewScanner(in),
+		out:     out,
+		main:    absPath(path),
+		sources: make(map[string][]string),
+	}
+	c.d.OnPause = c.pause
+
+	result, aborted := c.d.Run(path, true)
+	switch {
+	case aborted:
+		fmt.Fprintln(out, "script stopped")
+	case result != nil && result.Type() == object.ERROR_OBJ:
+		fmt.Fprintln(out, result.Inspect())
+		return 1
+	default:
+		fmt.Fprintln(out, "script finished")
+	}
+	return 0
+}
+
+func (c *cli) pause(reason string) {
+	c.frame = 0
+	c.printLocation(reason)
+	for {
+		fmt.Fprint(c.out, PROMPT)
+		if !c.scanner.Scan() {
+			c.d.Abort()
+		}
+		if c.command(strings.TrimSpace(c.scanner.Text())) {
+			return
+		}
+	}
+}
+
+// command runs one command and reports whether execution resumes.
+func (c *cli) command(line string) bool {
+	name, arg := line, ""
+	if i := strings.IndexByte(line, ' '); i >= 0 {
+		name, arg = line[:i], strings.TrimSpace(line[i+1:])
+	}
+	switch name {
+	case "":
+		return false
+	case "c", "continue":
+		c.d.Continue()
+		return true
+	case "s", "step":
+		c.d.StepIn()
+		return true
+	case "n", "next":
+		c.d.StepOver()
+		return true
+	case "o", "out":
+		c.d.StepOut()
+		return true
+	case "b", "break":
+		if file, line, ok := c.parseLocation(arg); ok {
+			c.d.AddBreakpoint(file, line)
+			fmt.Fprintf(c.out, "breakpoint set at %s:%d\n", filepath.Base(file), line)
+		}
+	case "clear":
+		if file, line, ok := c.parseLocation(arg); ok {
+			c.d.ClearBreakpoint(file, line)
+			fmt.Fprintf(c.out, "breakpoint cleared at %s:%d\n", filepath.Base(file), line)
+		}
+	case "l", "locals":
+		c.printLocals()
+	case "p", "print":
+		if arg == "" {
+			fmt.Fprintln(c.out, "usage: print expr")
+			return false
+		}
+		// statements such as let have no value to print
+		if result := c.d.Evaluate(arg, c.frame); result != nil {
+			fmt.Fprintln(c.out, result.Inspect())
+		}
+	case "bt", "stack":
+		c.printStack()
+	case "f", "frame":
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 0 || n >= len(c.d.Stack()) {
+			fmt.Fprintf(c.out, "no frame %q\n", arg)
+			return false
+		}
+		c.frame = n
+		c.printStack()
+	case "q", "quit":
+		c.d.Abort()
+	case "h", "help":
+		fmt.Fprint(c.out, HELP)
+	default:
+		fmt.Fprintf(c.out, "unknown command %q, type help for a list\n", name)
+	}
+	return false
+}
+
+// parseLocation reads "line" or "file:line"; files are relative to the
+// script being debugged.
+func (c *cli) parseLocation(arg string) (string, int, bool) {
+	file := c.main
+	if i := strings.LastIndexByte(arg, ':'); i >= 0 {
+		file = arg[:i]
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(filepath.Dir(c.main), file)
+		}
+		arg = arg[i+1:]
+	}
+	line, err := strconv.Atoi(arg)
+	if err != nil || line < 1 {
+		fmt.Fprintf(c.out, "invalid line %q\n", arg)
+		return "", 0, false
+	}
+	return file, line, true
+}
+
+func (c *cli) printLocation(reason string) {
+	frame := c.d.Stack()[0]
+	fmt.Fprintf(c.out, "stopped (%s) in %s at %s:%d\n",
+		reason, frame.Name, filepath.Base(frame.File), frame.Line)
+	if src := c.sourceLine(frame.File, frame.Line); src != "" {
+		fmt.Fprintf(c.out, "%5d\t%s\n", frame.Line, src)
+	}
+}
+
+func (c *cli) sourceLine(file string, line int) string {
+	lines, ok := c.sources[file]
+	if !ok {
+		src, err := os.ReadFile(file)
+		if err == nil {
+			lines = strings.Split(string(src), "\n")
+		}
+		c.sources[file] = lines
+	}
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	return strings.TrimSpace(lines[line-1])
+}
+
+// printLocals prints the bindings of the selected frame, then those of
+// the environments enclosing it that are not shadowed.
+func (c *cli) printLocals() {
+	seen := map[string]bool{}
+	for env := c.d.Stack()[c.frame].Env; env != nil; env = env.Outer() {
+		for _, name := range env.Names() {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			val, _ := env.Get(name)
+			fmt.Fprintf(c.out, "%s = %s\n", name, Summary(val))
+		}
+	}
+}
+
+func (c *cli) printStack() {
+	for i, frame := range c.d.Stack() {
+		marker := " "
+		if i == c.frame {
+			marker = "*"
+		}
+		fmt.Fprintf(c.out, "%s %d  %s at %s:%d\n",
+			marker, i, frame.Name, filepath.Base(frame.File), frame.Line)
+	}
+}
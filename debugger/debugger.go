@@ -0,0 +1,319 @@
+// Package debugger pauses the evaluation of scripts at breakpoints and
+// steps through them, using the hook of object.Runtime.
+package debugger
+
+import (
+	"errors"
+	"fmt"
+	"interpreter/ast"
+	"interpreter/evaluator"
+	"interpreter/lexer"
+	"interpreter/object"
+	"interpreter/parser"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Reasons execution paused for.
+const (
+	ReasonEntry      = "entry"
+	ReasonBreakpoint = "breakpoint"
+	ReasonStep       = "step"
+)
+
+type mode int
+
+const (
+	modeContinue mode = iota
+	modeStepIn
+	modeStepOver
+	modeStepOut
+)
+
+// Frame is a function call in progress, or the top level of the script.
+type Frame struct {
+	Name string // name of the called function, "main" for the top level
+	File string
+	Line int // line of the statement being evaluated
+	Env  *object.Environment
+
+	depth int
+}
+
+// Debugger runs a script, calling OnPause whenever it stops. Execution
+// resumes when OnPause returns, according to the last call of Continue,
+// StepIn, StepOver or StepOut made while paused.
+type Debugger struct {
+	Runtime *object.Runtime
+	// OnPause is called, on the goroutine running the script, each time
+	// execution stops.
+	OnPause func(reason string)
+
+	mu          sync.Mutex // guards breakpoints, which may change while running
+	breakpoints map[string]map[int]bool
+	mode        mode
+	stepDepth   int
+
+	frames      []*Frame
+	pendingCall *ast.CallExpression
+	last        location
+	entry       bool
+	evaluating  bool
+	stopping    int32 // set by Stop, read by the script goroutine
+}
+
+type location struct {
+	file  string
+	line  int
+	depth int
+}
+
+// errAbort unwinds the evaluation when the script is stopped.
+var errAbort = errors.New("debugger: aborted")
+
+func New(rt *object.Runtime) *Debugger {
+	d := &Debugger{
+		Runtime:     rt,
+		breakpoints: make(map[string]map[int]bool),
+	}
+	rt.Hook = d.hook
+	return d
+}
+
+// Run evaluates the script at path. With stopOnEntry, it pauses before
+// the first statement. It returns the error the script raised, if any.
+func (d *Debugger) Run(path string, stopOnEntry bool) (result object.Object, aborted bool) {
+	d.entry = stopOnEntry
+	defer func() {
+		if r := recover(); r != nil {
+			if r != errAbort {
+				panic(r)
+			}
+			aborted = true
+		}
+	}()
+	return evaluator.LoadFile(path, d.Runtime), false
+}
+
+// Abort stops the script; it must be called while paused, from OnPause.
+func (d *Debugger) Abort() {
+	panic(errAbort)
+}
+
+// Stop makes the script stop before its next statement. Unlike Abort it
+// can be called from any goroutine.
+func (d *Debugger) Stop() {
+	atomic.StoreInt32(&d.stopping, 1)
+}
+
+// SetBreakpoints replaces the breakpoints of a file.
+func (d *Debugger) SetBreakpoints(file string, lines []int) {
+	file = absPath(file)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.breakpoints[file] = make(map[int]bool)
+	for _, line := range lines {
+		d.breakpoints[file][line] = true
+	}
+}
+
+// AddBreakpoint sets a breakpoint on a line of a file.
+func (d *Debugger) AddBreakpoint(file string, line int) {
+	file = absPath(file)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.breakpoints[file] == nil {
+		d.breakpoints[file] = make(map[int]bool)
+	}
+	d.breakpoints[file][line] = true
+}
+
+// ClearBreakpoint removes the breakpoint on a line of a file.
+func (d *Debugger) ClearBreakpoint(file string, line int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.breakpoints[absPath(file)], line)
+}
+
+// Breakpoints lists the lines with breakpoints of a file.
+func (d *Debugger) Breakpoints(file string) []int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	lines := []int{}
+	for line := range d.breakpoints[absPath(file)] {
+		lines = append(lines, line)
+	}
+	sort.Ints(lines)
+	return lines
+}
+
+func (d *Debugger) hasBreakpoint(file string, line int) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.breakpoints[file][line]
+}
+
+func absPath(file string) string {
+	if abs, err := filepath.Abs(file); err == nil {
+		return abs
+	}
+	return file
+}
+
+// Continue resumes until the next breakpoint.
+func (d *Debugger) Continue() { d.mode = modeContinue }
+
+// StepIn resumes until the next statement, entering called functions.
+func (d *Debugger) StepIn() { d.mode = modeStepIn }
+
+// StepOver resumes until the next statement of the current function.
+func (d *Debugger) StepOver() { d.setStep(modeStepOver) }
+
+// StepOut resumes until the current function returns to its caller.
+func (d *Debugger) StepOut() { d.setStep(modeStepOut) }
+
+func (d *Debugger) setStep(m mode) {
+	d.mode = m
+	d.stepDepth = len(d.frames) - 1
+}
+
+// Stack returns the frames in progress, innermost first.
+func (d *Debugger) Stack() []*Frame {
+	stack := make([]*Frame, len(d.frames))
+	for i, f := range d.frames {
+		stack[len(d.frames)-1-i] = f
+	}
+	return stack
+}
+
+// Evaluate evaluates an expression in the environment of a frame of
+// the stack, 0 being the innermost.
+func (d *Debugger) Evaluate(input string, frame int) object.Object {
+	stack := d.Stack()
+	if frame < 0 || frame >= len(stack) {
+		return &object.Error{Message: fmt.Sprintf("no frame %d", frame)}
+	}
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return &object.Error{Message: strings.Join(p.Errors(), "; ")}
+	}
+	d.evaluating = true
+	defer func() { d.evaluating = false }()
+	return evaluator.Eval(program, stack[frame].Env)
+}
+
+// SUMMARY_ELEMENTS is the number of elements Summary shows of an array,
+// or of pairs of a hash.
+const SUMMARY_ELEMENTS = 10
+
+// Summary is a one-line Inspect of a value: functions are shown by
+// their parameters rather than their whole body, and arrays and hashes
+// by their first elements.
+func Summary(obj object.Object) string {
+	switch obj := obj.(type) {
+	case *object.Function:
+		params := []string{}
+		for _, p := range obj.Parameters {
+			params = append(params, p.Value)
+		}
+		return "fn(" + strings.Join(params, ", ") + ")"
+	case *object.Array:
+		elements := []string{}
+		for i, el := range obj.Elements {
+			if i == SUMMARY_ELEMENTS {
+				elements = append(elements, "…")
+				break
+			}
+			elements = append(elements, summaryElement(el))
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+	case *object.Hash:
+		pairs := []string{}
+		for i, pair := range obj.Ordered() {
+			if i == SUMMARY_ELEMENTS {
+				pairs = append(pairs, "…")
+				break
+			}
+			pairs = append(pairs, summaryElement(pair.Key)+": "+summaryElement(pair.Value))
+		}
+		return "{" + strings.Join(pairs, ", ") + "}"
+	}
+	return obj.Inspect()
+}
+
+// summaryElement summarizes a value held in a collection.
+func summaryElement(obj object.Object) string {
+	if s, ok := obj.(*object.String); ok {
+		return object.InspectElement(s)
+	}
+	return Summary(obj)
+}
+
+func (d *Debugger) hook(node ast.Node, env *object.Environment, depth int) {
+	if d.evaluating {
+		return
+	}
+	if atomic.LoadInt32(&d.stopping) != 0 {
+		d.Abort()
+	}
+	if call, ok := node.(*ast.CallExpression); ok {
+		d.pendingCall = call
+		return
+	}
+
+	d.updateFrames(env, depth)
+	frame := d.frames[len(d.frames)-1]
+	frame.Line = ast.NodeToken(node).Line
+	here := location{file: frame.File, line: frame.Line, depth: depth}
+
+	reason := ""
+	switch {
+	case d.entry:
+		reason = ReasonEntry
+		d.entry = false
+	case d.mode == modeStepIn:
+		reason = ReasonStep
+	case d.mode == modeStepOver && depth <= d.stepDepth:
+		reason = ReasonStep
+	case d.mode == modeStepOut && depth < d.stepDepth:
+		reason = ReasonStep
+	case here != d.last && d.hasBreakpoint(frame.File, frame.Line):
+		// statements of the same line are not a new hit
+		reason = ReasonBreakpoint
+	}
+	d.last = here
+
+	if reason != "" {
+		d.mode = modeContinue
+		d.OnPause(reason)
+	}
+}
+
+// updateFrames keeps one frame per call depth, pushing a frame when a
+// statement runs deeper than the last one and dropping returned calls.
+func (d *Debugger) updateFrames(env *object.Environment, depth int) {
+	if len(d.frames) > depth+1 {
+		d.frames = d.frames[:depth+1]
+	}
+	for len(d.frames) < depth+1 {
+		name := "main"
+		if len(d.frames) > 0 {
+			name = "anonymous"
+			if d.pendingCall != nil {
+				switch fn := d.pendingCall.Function.(type) {
+				case *ast.Identifier, *ast.MemberExpression:
+					name = fn.String()
+				}
+			}
+		}
+		d.frames = append(d.frames, &Frame{Name: name, depth: len(d.frames)})
+	}
+	d.pendingCall = nil
+	frame := d.frames[depth]
+	frame.Env = env
+	frame.File = env.File()
+}
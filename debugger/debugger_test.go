@@ -0,0 +1,176 @@
+package debugger
+
+import (
+	"bytes"
+	"fmt"
+	"interpreter/object"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const script = `let add = fn(a, b) {
+	let res = a + b;
+	res
+};
+let x = 1;
+let y = add(x, 2);
+let z = add(y, 3);
+`
+
+func writeScript(t *testing.T, src string) string {
+	path := filepath.Join(t.TempDir(), "main.tl")
+	if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// stop records where the debugger paused.
+func stop(d *Debugger, reason string) string {
+	stack := d.Stack()
+	return fmt.Sprintf("%s %s:%d depth=%d", reason, stack[0].Name, stack[0].Line, len(stack))
+}
+
+func TestStepping(t *testing.T) {
+	tests := []struct {
+		name        string
+		breakpoints []int
+		actions     []func(d *Debugger)
+		expected    []string
+	}{
+		{
+			"step in",
+			nil,
+			[]func(d *Debugger){(*Debugger).StepIn, (*Debugger).StepIn, (*Debugger).StepIn, (*Debugger).StepIn, (*Debugger).Continue},
+			[]string{"entry main:1 depth=1", "step main:5 depth=1", "step main:6 depth=1", "step add:2 depth=2", "step add:3 depth=2"},
+		},
+		{
+			"step over",
+			nil,
+			[]func(d *Debugger){(*Debugger).StepOver, (*Debugger).StepOver, (*Debugger).StepOver, (*Debugger).Continue},
+			[]string{"entry main:1 depth=1", "step main:5 depth=1", "step main:6 depth=1", "step main:7 depth=1"},
+		},
+		{
+			"step over stops at breakpoints",
+			[]int{2},
+			[]func(d *Debugger){(*Debugger).Continue, (*Debugger).StepOver, (*Debugger).StepOver},
+			[]string{"entry main:1 depth=1", "breakpoint add:2 depth=2", "step add:3 depth=2", "step main:7 depth=1", "breakpoint add:2 depth=2"},
+		},
+		{
+			"step out",
+			[]int{2},
+			[]func(d *Debugger){(*Debugger).Continue, (*Debugger).StepOut, (*Debugger).Continue},
+			[]string{"entry main:1 depth=1", "breakpoint add:2 depth=2", "step main:7 depth=1", "breakpoint add:2 depth=2"},
+		},
+	}
+	for _, tt := range tests {
+		path := writeScript(t, script)
+		d := New(object.NewRuntime())
+		d.SetBreakpoints(path, tt.breakpoints)
+		var stops []string
+		d.OnPause = func(reason string) {
+			stops = append(stops, stop(d, reason))
+			if len(stops) <= len(tt.actions) {
+				tt.actions[len(stops)-1](d)
+			}
+		}
+		result, aborted := d.Run(path, true)
+		if aborted || (result != nil && result.Type() == object.ERROR_OBJ) {
+			t.Fatalf("%s: run failed. result=%v aborted=%t", tt.name, result, aborted)
+		}
+		if strings.Join(stops, "\n") != strings.Join(tt.expected, "\n") {
+			t.Errorf("%s: wrong stops.\nexpected=%q\ngot=%q", tt.name, tt.expected, stops)
+		}
+	}
+}
+
+func TestEvaluateInFrame(t *testing.T) {
+	path := writeScript(t, script)
+	d := New(object.NewRuntime())
+	d.AddBreakpoint(path, 3)
+
+	var results []string
+	d.OnPause = func(reason string) {
+		results = append(results,
+			d.Evaluate("res * 10", 0).Inspect(),
+			d.Evaluate("x", 1).Inspect(),
+			d.Evaluate("res", 1).Inspect())
+		d.Abort()
+	}
+	if _, aborted := d.Run(path, false); !aborted {
+		t.Fatalf("script not aborted")
+	}
+	expected := []string{"30", "1", "ERROR: identifier not found: res"}
+	if strings.Join(results, "|") != strings.Join(expected, "|") {
+		t.Errorf("wrong results. expected=%q, got=%q", expected, results)
+	}
+
+	// aborting unwinds the calls in progress
+	if d.Runtime.Depth != 0 {
+		t.Errorf("wrong depth after abort. got=%d", d.Runtime.Depth)
+	}
+}
+
+func TestCLI(t *testing.T) {
+	path := writeScript(t, script)
+	in := strings.NewReader(strings.Join([]string{
+		"b 2",
+		"c",
+		"locals",
+		"bt",
+		"print a + b",
+		"frame 1",
+		"p x",
+		"clear 2",
+		"c",
+	}, "\n"))
+	var out bytes.Buffer
+	if status := Start(path, object.NewRuntime(), in, &out); status != 0 {
+		t.Fatalf("wrong status. got=%d\n%s", status, out.String())
+	}
+
+	expected := []string{
+		"stopped (entry) in main at main.tl:1",
+		"breakpoint set at main.tl:2",
+		"stopped (breakpoint) in add at main.tl:2",
+		"    2\tlet res = a + b;",
+		"a = 1\nb = 2\nadd = fn(a, b)\nx = 1\n",
+		"* 0  add at main.tl:2\n  1  main at main.tl:6\n",
+		"(debug) 3\n",
+		"  0  add at main.tl:2\n* 1  main at main.tl:6\n",
+		"(debug) 1\n",
+		"breakpoint cleared at main.tl:2",
+		"script finished",
+	}
+	output := out.String()
+	for _, e := range expected {
+		if !strings.Contains(output, e) {
+			t.Errorf("output does not contain %q.\n%s", e, output)
+		}
+	}
+}
+
+func TestCLIPrintWithoutValue(t *testing.T) {
+	path := writeScript(t, script)
+	in := strings.NewReader(strings.Join([]string{"p", "p let z = 5", "p z", "c"}, "\n"))
+	var out bytes.Buffer
+	if status := Start(path, object.NewRuntime(), in, &out); status != 0 {
+		t.Fatalf("wrong status. got=%d\n%s", status, out.String())
+	}
+
+	expected := "(debug) usage: print expr\n(debug) (debug) 5\n"
+	if !strings.Contains(out.String(), expected) {
+		t.Errorf("output does not contain %q.\n%s", expected, out.String())
+	}
+}
+
+func TestCLIQuit(t *testing.T) {
+	path := writeScript(t, script)
+	var out bytes.Buffer
+	Start(path, object.NewRuntime(), strings.NewReader("q\n"), &out)
+	if !strings.Contains(out.String(), "script stopped") {
+		t.Errorf("script not stopped.\n%s", out.String())
+	}
+}
@@ -0,0 +1,348 @@
+package evaluator
+
+import (
+	"interpreter/object"
+	"sort"
+)
+
+// The collection builtins call back into functions, whose evaluation
+// refers to the builtins, so they are registered once these exist.
+func init() {
+	builtins["map"] = &object.Builtin{MinArgs: 2, MaxArgs: 2, Fn: mapArray}
+	builtins["filter"] = &object.Builtin{MinArgs: 2, MaxArgs: 2, Fn: filterArray}
+	builtins["reduce"] = &object.Builtin{MinArgs: 2, MaxArgs: 3, Fn: reduceArray}
+	builtins["each"] = &object.Builtin{MinArgs: 2, MaxArgs: 2, Fn: eachArray}
+	builtins["any"] = &object.Builtin{MinArgs: 1, MaxArgs: 2, Fn: anyArray}
+	builtins["all"] = &object.Builtin{MinArgs: 1, MaxArgs: 2, Fn: allArray}
+	builtins["find"] = &object.Builtin{MinArgs: 2, MaxArgs: 2, Fn: findArray}
+	builtins["sort"] = &object.Builtin{MinArgs: 1, MaxArgs: 2, Fn: sortArray}
+	builtins["reverse"] = &object.Builtin{MinArgs: 1, MaxArgs: 1, Fn: reverse}
+	builtins["zip"] = &object.Builtin{MinArgs: 1, MaxArgs: -1, Fn: zip}
+	builtins["range"] = &object.Builtin{MinArgs: 1, MaxArgs: 3, Fn: rangeArray}
+	builtins["sum"] = &object.Builtin{MinArgs: 1, MaxArgs: 1, Fn: sum}
+}
+
+// arrayArg returns the elements of an array argument of the builtin name.
+func arrayArg(name string, arg object.Object) ([]object.Object, *object.Error) {
+	array, ok := arg.(*object.Array)
+	if !ok {
+		return nil, newError("argument to `%s` not supported, got %s", name, arg.Type())
+	}
+	return array.Elements, nil
+}
+
+// callbackArgs returns the elements of the array and the function the
+// builtin name was called with.
+func callbackArgs(name string, args []object.Object) ([]object.Object, object.Object, *object.Error) {
+	elements, err := arrayArg(name, args[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	switch fn := args[1].(type) {
+	case *object.Function, *object.Builtin:
+		return elements, fn, nil
+	default:
+		return nil, nil, newError("argument to `%s` not supported, got %s", name, args[1].Type())
+	}
+}
+
+// mapArray returns the results of fn on each element.
+func mapArray(env *object.Environment, args ...object.Object) object.Object {
+	elements, fn, err := callbackArgs("map", args)
+	if err != nil {
+		return err
+	}
+	results := make([]object.Object, len(elements))
+	for i, el := range elements {
+		result := callFunction(fn, []object.Object{el}, env)
+		if isError(result) {
+			return result
+		}
+		results[i] = result
+	}
+	return &object.Array{Elements: results}
+}
+
+// filterArray returns the elements fn is true for.
+func filterArray(env *object.Environment, args ...object.Object) object.Object {
+	elements, fn, err := callbackArgs("filter", args)
+	if err != nil {
+		return err
+	}
+	results := []object.Object{}
+	for _, el := range elements {
+		result := callFunction(fn, []object.Object{el}, env)
+		if isError(result) {
+			return result
+		}
+		if isTrue(result) {
+			results = append(results, el)
+		}
+	}
+	return &object.Array{Elements: results}
+}
+
+// reduceArray folds the elements into fn(fn(initial, first), second)...,
+// starting from the first element if there is no initial value.
+func reduceArray(env *object.Environment, args ...object.Object) object.Object {
+	elements, fn, err := callbackArgs("reduce", args)
+	if err != nil {
+		return err
+	}
+	var acc object.Object
+	if len(args) == 3 {
+		acc = args[2]
+	} else if len(elements) == 0 {
+		return newError("`reduce` of an empty array with no initial value")
+	} else {
+		acc, elements = elements[0], elements[1:]
+	}
+	for _, el := range elements {
+		acc = callFunction(fn, []object.Object{acc, el}, env)
+		if isError(acc) {
+			return acc
+		}
+	}
+	return acc
+}
+
+// eachArray calls fn on each element for its effects.
+func eachArray(env *object.Environment, args ...object.Object) object.Object {
+	elements, fn, err := callbackArgs("each", args)
+	if err != nil {
+		return err
+	}
+	for _, el := range elements {
+		if result := callFunction(fn, []object.Object{el}, env); isError(result) {
+			return result
+		}
+	}
+	return NULL
+}
+
+func anyArray(env *object.Environment, args ...object.Object) object.Object {
+	return testElements("any", true, env, args)
+}
+
+func allArray(env *object.Environment, args ...object.Object) object.Object {
+	return testElements("all", false, env, args)
+}
+
+// testElements reports whether the elements, or the results of the
+// optional fn on them, are all true, or, for any, whether one is. It
+// stops at the first element that decides.
+func testElements(name string, any bool, env *object.Environment, args []object.Object) object.Object {
+	elements, err := arrayArg(name, args[0])
+	var fn object.Object
+	if len(args) == 2 {
+		elements, fn, err = callbackArgs(name, args)
+	}
+	if err != nil {
+		return err
+	}
+	for _, el := range elements {
+		result := el
+		if fn != nil {
+			if result = callFunction(fn, []object.Object{el}, env); isError(result) {
+				return result
+			}
+		}
+		if isTrue(result) == any {
+			return nativeBooleanVariable(any)
+		}
+	}
+	return nativeBooleanVariable(!any)
+}
+
+// findArray returns the first element fn is true for, or null.
+func findArray(env *object.Environment, args ...object.Object) object.Object {
+	elements, fn, err := callbackArgs("find", args)
+	if err != nil {
+		return err
+	}
+	for _, el := range elements {
+		result := callFunction(fn, []object.Object{el}, env)
+		if isError(result) {
+			return result
+		}
+		if isTrue(result) {
+			return el
+		}
+	}
+	return NULL
+}
+
+// sortArray returns the elements in increasing order, which is that of
+// numbers or of strings, or given by a comparator: fn(a, b) is true, or
+// a negative number, when a goes before b. Equal elements keep their
+// order.
+func sortArray(env *object.Environment, args ...object.Object) object.Object {
+	elements, err := arrayArg("sort", args[0])
+	if err != nil {
+		return err
+	}
+	var less func(a, b object.Object) (bool, object.Object)
+	if len(args) == 2 {
+		_, fn, err := callbackArgs("sort", args)
+		if err != nil {
+			return err
+		}
+		less = func(a, b object.Object) (bool, object.Object) {
+			return comparatorLess(fn, a, b, env)
+		}
+	} else {
+		less = naturalLess
+	}
+
+	sorted := make([]object.Object, len(elements))
+	copy(sorted, elements)
+	var sortErr object.Object
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sortErr != nil {
+			return false
+		}
+		isLess, err := less(sorted[i], sorted[j])
+		if err != nil {
+			sortErr = err
+		}
+		return isLess
+	})
+	if sortErr != nil {
+		return sortErr
+	}
+	return &object.Array{Elements: sorted}
+}
+
+func comparatorLess(fn, a, b object.Object, env *object.Environment) (bool, object.Object) {
+	result := callFunction(fn, []object.Object{a, b}, env)
+	switch result := result.(type) {
+	case *object.Error:
+		return false, result
+	case *object.Boolean:
+		return result.Value, nil
+	case *object.Integer:
+		return result.Value < 0, nil
+	case *object.BigInteger:
+		return result.Value.Sign() < 0, nil
+	case *object.Float:
+		return result.Value < 0, nil
+	default:
+		return false, newError("`sort` comparator must return BOOLEAN or a number, got %s", result.Type())
+	}
+}
+
+// naturalLess orders numbers and strings.
+func naturalLess(a, b object.Object) (bool, object.Object) {
+	switch {
+	case isNumber(a) && isNumber(b):
+		return numberLess(a, b), nil
+	case a.Type() == object.STRING_OBJ && b.Type() == object.STRING_OBJ:
+		return a.(*object.String).Value < b.(*object.String).Value, nil
+	default:
+		return false, newError("`sort` cannot compare %s and %s without a comparator", a.Type(), b.Type())
+	}
+}
+
+// reverse returns the elements of an array, or the characters of a
+// string, in reverse order.
+func reverse(env *object.Environment, args ...object.Object) object.Object {
+	switch arg := args[0].(type) {
+	case *object.Array:
+		n := len(arg.Elements)
+		elements := make([]object.Object, n)
+		for i, el := range arg.Elements {
+			elements[n-1-i] = el
+		}
+		return &object.Array{Elements: elements}
+	case *object.String:
+		runes := []rune(arg.Value)
+		for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+			runes[i], runes[j] = runes[j], runes[i]
+		}
+		return &object.String{Value: string(runes)}
+	default:
+		return newError("argument to `reverse` not supported, got %s", args[0].Type())
+	}
+}
+
+// zip returns arrays of the elements at the same index of each array,
+// as many as the shortest array has.
+func zip(env *object.Environment, args ...object.Object) object.Object {
+	arrays := make([][]object.Object, len(args))
+	n := -1
+	for i, arg := range args {
+		elements, err := arrayArg("zip", arg)
+		if err != nil {
+			return err
+		}
+		arrays[i] = elements
+		if n < 0 || len(elements) < n {
+			n = len(elements)
+		}
+	}
+	results := make([]object.Object, n)
+	for i := range results {
+		tuple := make([]object.Object, len(arrays))
+		for j, elements := range arrays {
+			tuple[j] = elements[i]
+		}
+		results[i] = &object.Array{Elements: tuple}
+	}
+	return &object.Array{Elements: results}
+}
+
+// MAX_RANGE_LENGTH is the most elements `range` returns.
+const MAX_RANGE_LENGTH = 1 << 24
+
+// rangeArray returns the integers from start, 0 by default, up to but
+// not including end, counting by step, 1 by default.
+func rangeArray(env *object.Environment, args ...object.Object) object.Object {
+	bounds := []int64{0, 0, 1}
+	for i, arg := range args {
+		n, err := intArg("range", arg)
+		if err != nil {
+			return err
+		}
+		bounds[i] = n
+	}
+	if len(args) == 1 {
+		bounds[0], bounds[1] = 0, bounds[0]
+	}
+	start, end, step := bounds[0], bounds[1], bounds[2]
+	if step == 0 {
+		return newError("`range` step must not be zero")
+	}
+	// the differences of int64s fit an uint64
+	var n uint64
+	switch {
+	case step > 0 && start < end:
+		n = (uint64(end-start)-1)/uint64(step) + 1
+	case step < 0 && start > end:
+		n = (uint64(start-end)-1)/uint64(-step) + 1
+	}
+	if n > MAX_RANGE_LENGTH {
+		return newError("`range` of more than %d elements", MAX_RANGE_LENGTH)
+	}
+	elements := make([]object.Object, n)
+	for i := range elements {
+		elements[i] = &object.Integer{Value: start + int64(i)*step}
+	}
+	return &object.Array{Elements: elements}
+}
+
+// sum adds the elements of an array of numbers; it is an integer unless
+// one of them is a float.
+func sum(env *object.Environment, args ...object.Object) object.Object {
+	elements, err := arrayArg("sum", args[0])
+	if err != nil {
+		return err
+	}
+	var total object.Object = &object.Integer{Value: 0}
+	for _, el := range elements {
+		if !isNumber(el) {
+			return newError("element of `sum` argument not supported, got %s", el.Type())
+		}
+		total = evalInfixExpression(total, "+", el)
+	}
+	return total
+}
@@ -0,0 +1,82 @@
+package evaluator
+
+import "testing"
+
+func TestCollectionBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`map([1, 2, 3], fn(x) { x * 2 })`, []int{2, 4, 6}},
+		{`map(["a", "bc"], len)`, []int{1, 2}},
+		{`map([], fn(x) { y })`, []int{}},
+		{`let k = 10; map([1, 2], fn(x) { x + k })`, []int{11, 12}},
+		{`filter([1, 2, 3, 4], fn(x) { x > 2 })`, []int{3, 4}},
+		{`reduce([1, 2, 3, 4], fn(acc, x) { acc * x })`, 24},
+		{`reduce([1, 2], fn(acc, x) { acc + x }, 10)`, 13},
+		{`reduce([], fn(acc, x) { acc + x }, 0)`, 0},
+		{`reduce([], fn(acc, x) { acc + x })`, "`reduce` of an empty array with no initial value"},
+		{`each([1, 2], fn(x) { x })`, nil},
+		{`any([1, 2, 3], fn(x) { x > 2 })`, true},
+		{`any([], fn(x) { true })`, false},
+		{`all([1, 2, 3], fn(x) { x > 0 })`, true},
+		{`all([true, false])`, false},
+		{`any([false, 0])`, true},
+		{`any([1, "a"], fn(x) { x > 0 })`, true},
+		{`all([1, "a"], fn(x) { x > 0 })`, "type mismatch: STRING > INTEGER"},
+		{`find([1, 5, 8], fn(x) { x > 4 })`, 5},
+		{`find([1], fn(x) { x > 4 })`, nil},
+		{`sort(["b", "c", "a"])`, []string{"a", "b", "c"}},
+		{`sort([3, 1, 2], fn(a, b) { a > b })`, []int{3, 2, 1}},
+		{`sort([3, 1, 2], fn(a, b) { a - b })`, []int{1, 2, 3}},
+		{`sort([1, "a"])`, "`sort` cannot compare STRING and INTEGER without a comparator"},
+		{`sort([1, 2], fn(a, b) { "a" })`, "`sort` comparator must return BOOLEAN or a number, got STRING"},
+		{`sort([2, 1], fn(a, b) { a + "x" })`, "type mismatch: INTEGER + STRING"},
+		{`reverse([1, 2, 3])`, []int{3, 2, 1}},
+		{`reverse("héllo")`, "olléh"},
+		{`reverse(1)`, "argument to `reverse` not supported, got INTEGER"},
+		{`zip([1, 2, 3], ["a", "b"])`, []interface{}{[]interface{}{1, "a"}, []interface{}{2, "b"}}},
+		{`zip([1], 2)`, "argument to `zip` not supported, got INTEGER"},
+		{`range(4)`, []int{0, 1, 2, 3}},
+		{`range(2, 5)`, []int{2, 3, 4}},
+		{`range(5, 0, -2)`, []int{5, 3, 1}},
+		{`range(3, 1)`, []int{}},
+		{`range(9223372036854775806, 9223372036854775807, 5)`, []int{9223372036854775806}},
+		{`range(1, 2, 0)`, "`range` step must not be zero"},
+		{`sum([1, 2, 3])`, 6},
+		{`sum([])`, 0},
+		{`sum([1, "a"])`, "element of `sum` argument not supported, got STRING"},
+		{`map([1], 2)`, "argument to `map` not supported, got INTEGER"},
+		{`map(1, fn(x) { x })`, "argument to `map` not supported, got INTEGER"},
+		{`map([1, 2], fn(x) { x + y })`, "identifier not found: y"},
+		{`map([1], fn(a, b) { a })`, "wrong number of arguments. got=1, want=2"},
+		{`filter([1], fn(x) { return x; 0 })`, []int{1}},
+		// a function with an empty body gives null
+		{`map([1, 2], fn(x) {})`, []interface{}{nil, nil}},
+		{`json_stringify(map([1], fn(x) {}))`, "[null]"},
+		{`filter([1], fn(x) {})`, []int{}},
+		{`reduce([1, 2], fn(acc, x) {}, 0)`, nil},
+		{`each([1], fn(x) {})`, nil},
+		{`any([1], fn(x) {})`, false},
+		{`find([1], fn(x) {})`, nil},
+		{`sort([2, 1], fn(a, b) {})`, "`sort` comparator must return BOOLEAN or a number, got NULL"},
+		{`range(0, 9223372036854775807)`, "`range` of more than 16777216 elements"},
+		{`range(-9223372036854775808, 9223372036854775807, 9223372036854775807)`, []int{-9223372036854775808, -1, 9223372036854775806}},
+		{`range(9223372036854775807, -9223372036854775808, -9223372036854775808)`, []int{9223372036854775807, -1}},
+	}
+	for _, tt := range tests {
+		testValue(t, tt.input, testEval(tt.input), tt.expected)
+	}
+
+	inspects := map[string]string{
+		`sort([3, 1.5, 2])`: "[1.5, 2, 3]",
+		// equal elements keep their order
+		`sort([[2, "x"], [1, "y"], [2, "a"], [1, "b"]], fn(a, b) { a[0] < b[0] })`: `[[1, "y"], [1, "b"], [2, "x"], [2, "a"]]`,
+		`sum([1, 2.5])`: "3.5",
+	}
+	for input, expected := range inspects {
+		if got := testEval(input).Inspect(); got != expected {
+			t.Errorf("wrong result for %s. expected=%s, got=%s", input, expected, got)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package evaluator
+
+import "interpreter/object"
+
+// objectsEqual is the equality of `==`: numbers are equal by value,
+// whether integers or floats, strings, booleans, regexes and times by
+// what they hold, and arrays and hashes when they have equal elements,
+// whatever the order of the keys of hashes. Functions, builtins and
+// modules are only equal to themselves, and null only to null.
+func objectsEqual(a, b object.Object) bool {
+	if isNumber(a) && isNumber(b) {
+		if a.Type() == object.INTEGER_OBJ && b.Type() == object.INTEGER_OBJ {
+			return compareIntegers(a, b) == 0
+		}
+		return toFloat(a) == toFloat(b)
+	}
+	switch a := a.(type) {
+	case *object.String:
+		b, ok := b.(*object.String)
+		return ok && a.Value == b.Value
+	case *object.Boolean:
+		b, ok := b.(*object.Boolean)
+		return ok && a.Value == b.Value
+	case *object.Null:
+		return b.Type() == object.NULL_OBJ
+	case *object.Regex:
+		b, ok := b.(*object.Regex)
+		return ok && a.Pattern == b.Pattern && a.Flags == b.Flags
+	case *object.Time:
+		b, ok := b.(*object.Time)
+		return ok && a.Value.Equal(b.Value)
+	case *object.Array:
+		b, ok := b.(*object.Array)
+		if !ok || len(a.Elements) != len(b.Elements) {
+			return false
+		}
+		if a == b {
+			return true
+		}
+		for i, el := range a.Elements {
+			if !objectsEqual(el, b.Elements[i]) {
+				return false
+			}
+		}
+		return true
+	case *object.Hash:
+		b, ok := b.(*object.Hash)
+		if !ok || len(a.Pairs) != len(b.Pairs) {
+			return false
+		}
+		if a == b {
+			return true
+		}
+		for key, pair := range a.Pairs {
+			other, ok := b.Pairs[key]
+			if !ok || !objectsEqual(pair.Value, other.Value) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
+
+// same reports whether its arguments are the same value: the same array,
+// hash, function or module rather than an equal one. Other values have
+// no identity, and are the same when they are equal and of one type.
+func same(env *object.Environment, args ...object.Object) object.Object {
+	a, b := args[0], args[1]
+	switch a.(type) {
+	case *object.Array, *object.Hash, *object.Function, *object.Builtin, *object.Module:
+		return nativeBooleanVariable(a == b)
+	}
+	return nativeBooleanVariable(a.Type() == b.Type() && objectsEqual(a, b))
+}
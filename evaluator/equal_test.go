@@ -0,0 +1,53 @@
+package evaluator
+
+import "testing"
+
+func TestEquality(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`"a" == "a"`, true},
+		{`"a" != "a"`, false},
+		{`"a" == "b"`, false},
+		{`"a" < "b"`, true},
+		{`"b" > "abc"`, true},
+		{`"B" < "a"`, true},
+		{`"" < "a"`, true},
+		{`"a" == 1`, false},
+		{`"1" != 1`, true},
+		{`1 == 1.0`, true},
+		{`true == true`, true},
+		{`true == 1`, false},
+		{`[1, "a", [true]] == [1, "a", [true]]`, true},
+		{`[1, 2] == [1, 2, 3]`, false},
+		{`[1, 2] != [2, 1]`, true},
+		{`[1] == [1.0]`, true},
+		{`[] == {}`, false},
+		{`[null] == [null]`, true},
+		{`{"a": 1, "b": [2]} == {"b": [2], "a": 1}`, true},
+		{`{"a": 1} == {"a": 2}`, false},
+		{`{"a": 1} == {"a": 1, "b": 1}`, false},
+		{`{1: true} == {"1": true}`, false},
+		{`/a+/i == /a+/i`, true},
+		{`/a+/i == /a+/`, false},
+		{`let f = fn(x) { x }; f == f`, true},
+		{`fn(x) { x } == fn(x) { x }`, false},
+		{`len == len`, true},
+		{`let t = now(); [t] == [t + 0]`, true},
+		{`same([1], [1])`, false},
+		{`let a = [1]; same(a, a)`, true},
+		{`let h = {}; let g = h; same(g, h)`, true},
+		{`same({}, {})`, false},
+		{`same(1, 1)`, true},
+		{`same(1, 1.0)`, false},
+		{`same("a", "a")`, true},
+		{`same(null, null)`, true},
+		{`same(len, len)`, true},
+		{`"a" - "b"`, "unknown operator: STRING - STRING"},
+		{`[1] < [2]`, "unknown operator: ARRAY < ARRAY"},
+	}
+	for _, tt := range tests {
+		testValue(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
@@ -4,6 +4,10 @@ import (
 	"fmt"
 	"interpreter/ast"
 	"interpreter/object"
+	"math"
+	"math/big"
+	"sort"
+	"unicode/utf8"
 )
 
 var (
@@ -14,14 +18,16 @@ var (
 
 var builtins = map[string]*object.Builtin{
 	"len": &object.Builtin{
-		Fn: func(args ...object.Object) object.Object {
-			if len(args) != 1 {
-				return newError("wrong number of arguments. got=%d, want=1",
-					len(args))
-			}
+		MinArgs: 1,
+		MaxArgs: 1,
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
 			switch arg := args[0].(type) {
 			case *object.String:
-				return &object.Integer{Value: int64(len(arg.Value))}
+				return &object.Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
+			case *object.Array:
+				return &object.Integer{Value: int64(len(arg.Elements))}
+			case *object.Hash:
+				return &object.Integer{Value: int64(len(arg.Keys))}
 			default:
 				return newError("argument to `len` not supported, got %s",
 					args[0].Type())
@@ -29,16 +35,64 @@ var builtins = map[string]*object.Builtin{
 		},
 	},
 	"echo": &object.Builtin{
-		Fn: func(args ...object.Object) object.Object {
+		MinArgs: 0,
+		MaxArgs: -1,
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
 			for _, arg := range args {
-				fmt.Println(arg.Inspect())
+				fmt.Fprintln(env.Runtime().Stdout, arg.Inspect())
 			}
 			return NULL
 		},
 	},
+	"json_parse":     &object.Builtin{MinArgs: 1, MaxArgs: 1, Fn: jsonParse},
+	"json_stringify": &object.Builtin{MinArgs: 1, MaxArgs: 2, Fn: jsonStringify},
+	"regex":          &object.Builtin{MinArgs: 1, MaxArgs: 2, Fn: regexBuiltin},
+	"read_file":      &object.Builtin{MinArgs: 1, MaxArgs: 1, Fn: readFile},
+	"write_file":     &object.Builtin{MinArgs: 2, MaxArgs: 2, Fn: writeFile},
+	"append_file":    &object.Builtin{MinArgs: 2, MaxArgs: 2, Fn: appendFile},
+	"list_dir":       &object.Builtin{MinArgs: 1, MaxArgs: 1, Fn: listDir},
+	"exists":         &object.Builtin{MinArgs: 1, MaxArgs: 1, Fn: exists},
+	"remove":         &object.Builtin{MinArgs: 1, MaxArgs: 1, Fn: remove},
+	"now":            &object.Builtin{MinArgs: 0, MaxArgs: 0, Fn: timeNow},
+	"unix":           &object.Builtin{MinArgs: 0, MaxArgs: 1, Fn: timeUnix},
+	"sleep":          &object.Builtin{MinArgs: 1, MaxArgs: 1, Fn: timeSleep},
+	"format_time":    &object.Builtin{MinArgs: 1, MaxArgs: 2, Fn: formatTime},
+	"parse_time":     &object.Builtin{MinArgs: 1, MaxArgs: 2, Fn: parseTime},
+	"duration":       &object.Builtin{MinArgs: 1, MaxArgs: 1, Fn: duration},
+	"random":         &object.Builtin{MinArgs: 0, MaxArgs: 0, Fn: random},
+	"random_int":     &object.Builtin{MinArgs: 2, MaxArgs: 2, Fn: randomInt},
+	"choice":         &object.Builtin{MinArgs: 1, MaxArgs: 1, Fn: choice},
+	"shuffle":        &object.Builtin{MinArgs: 1, MaxArgs: 1, Fn: shuffle},
+	"seed":           &object.Builtin{MinArgs: 1, MaxArgs: 1, Fn: seed},
+	"type":           &object.Builtin{MinArgs: 1, MaxArgs: 1, Fn: typeName},
+	"is_int":         typePredicate(object.INTEGER_OBJ),
+	"is_float":       typePredicate(object.FLOAT_OBJ),
+	"is_number":      typePredicate(object.INTEGER_OBJ, object.FLOAT_OBJ),
+	"is_string":      typePredicate(object.STRING_OBJ),
+	"is_bool":        typePredicate(object.BOOLEAN_OBJ),
+	"is_null":        typePredicate(object.NULL_OBJ),
+	"is_array":       typePredicate(object.ARRAY_OBJ),
+	"is_hash":        typePredicate(object.HASH_OBJ),
+	"is_fn":          typePredicate(object.FUNCTION_OBJ, object.BUILTIN_OBJ),
+	"str":            &object.Builtin{MinArgs: 1, MaxArgs: 1, Fn: toString},
+	"int":            &object.Builtin{MinArgs: 1, MaxArgs: 1, Fn: toInt},
+	"float":          &object.Builtin{MinArgs: 1, MaxArgs: 1, Fn: toFloatBuiltin},
+	"bool":           &object.Builtin{MinArgs: 1, MaxArgs: 1, Fn: toBool},
+	"inspect":        &object.Builtin{MinArgs: 1, MaxArgs: 1, Fn: inspect},
+	"same":           &object.Builtin{MinArgs: 2, MaxArgs: 2, Fn: same},
 }
 
+// Eval evaluates node in env. Errors are positioned at the innermost
+// node they are raised at.
 func Eval(node ast.Node, env *object.Environment) object.Object {
+	result := evalNode(node, env)
+	if err, ok := result.(*object.Error); ok && err.Node == nil {
+		err.Node, err.File = node, env.File()
+	}
+	return result
+}
+
+func evalNode(node ast.Node, env *object.Environment) object.Object {
 	switch node := node.(type) {
 	// Statements
 	case *ast.Program:
@@ -50,16 +104,27 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 			return val
 		}
 		env.Set(node.Name.Value, val)
+	case *ast.ExportStatement:
+		return evalExportStatement(node, env)
 	case *ast.Identifier:
 		return evalIdentifier(node, env)
 	case *ast.ExpressionStatement:
 		return Eval(node.Expression, env)
 	case *ast.IntegerLiteral:
+		if node.Big != nil {
+			return integerObject(node.Big)
+		}
 		return &object.Integer{Value: node.Value}
+	case *ast.FloatLiteral:
+		return &object.Float{Value: node.Value}
 	case *ast.Boolean:
 		return nativeBooleanVariable(node.Value)
+	case *ast.NullLiteral:
+		return NULL
 	case *ast.StringLiteral:
 		return &object.String{Value: node.Value}
+	case *ast.RegexLiteral:
+		return compileRegex(node.Pattern, node.Flags)
 	case *ast.PrefixExpression:
 		right := Eval(node.Right, env)
 		if isError(right) {
@@ -71,8 +136,11 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		if isError(left) {
 			return left
 		}
+		if node.Operator == "??" && left != NULL {
+			return left
+		}
 		right := Eval(node.Right, env)
-		if isError(right) {
+		if isError(right) || node.Operator == "??" {
 			return right
 		}
 		return evalInfixExpression(left, node.Operator, right)
@@ -81,6 +149,10 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return evalBlockStatement(node, env)
 	case *ast.IfExpression:
 		return evalIfExpression(node, env)
+	case *ast.ConditionalExpression:
+		return evalConditionalExpression(node, env)
+	case *ast.MatchExpression:
+		return evalMatchExpression(node, env)
 
 	case *ast.ReturnStatement:
 		val := Eval(node.ReturnValue, env)
@@ -95,14 +167,43 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return &object.Function{Parameters: params, Body: body, Env: env}
 	case *ast.CallExpression:
 		function := Eval(node.Function, env)
-		if isError(function) {
+		if isError(function) || node.Optional && function == NULL {
 			return function
 		}
 		args := evalExpressions(node.Arguments, env)
 		if len(args) == 1 && isError(args[0]) {
 			return args[0]
 		}
-		return callFunction(function, args)
+		trace(node, env)
+		return callFunction(function, args, env)
+	case *ast.IndexExpression:
+		left := Eval(node.Left, env)
+		if isError(left) || node.Optional && left == NULL {
+			return left
+		}
+		index := Eval(node.Index, env)
+		if isError(index) {
+			return index
+		}
+		return evalIndexExpression(left, index)
+	case *ast.SliceExpression:
+		return evalSliceExpression(node, env)
+	case *ast.ArrayLiteral:
+		elements := evalExpressions(node.Elements, env)
+		if len(elements) == 1 && isError(elements[0]) {
+			return elements[0]
+		}
+		return &object.Array{Elements: elements}
+	case *ast.HashLiteral:
+		return evalHashLiteral(node, env)
+	case *ast.MemberExpression:
+		obj := Eval(node.Object, env)
+		if isError(obj) || node.Optional && obj == NULL {
+			return obj
+		}
+		return evalMemberExpression(obj, node.Property.Value)
+	case *ast.ImportExpression:
+		return evalImportExpression(node, env)
 	}
 
 	return nil
@@ -111,6 +212,10 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 func evalProgram(program *ast.Program, env *object.Environment) object.Object {
 	var result object.Object
 	for _, statement := range program.Statements {
+		if err := canceled(env); err != nil {
+			return err
+		}
+		trace(statement, env)
 		result = Eval(statement, env)
 
 		switch result := result.(type) {
@@ -126,6 +231,10 @@ func evalProgram(program *ast.Program, env *object.Environment) object.Object {
 func evalBlockStatement(bs *ast.BlockStatement, env *object.Environment) object.Object {
 	var result object.Object
 	for _, statement := range bs.Statements {
+		if err := canceled(env); err != nil {
+			return err
+		}
+		trace(statement, env)
 		result = Eval(statement, env)
 		if result != nil {
 			rt := result.Type()
@@ -134,9 +243,29 @@ func evalBlockStatement(bs *ast.BlockStatement, env *object.Environment) object.
 			}
 		}
 	}
+	if result == nil {
+		//an empty block, or one ending in a let, is still a value
+		return NULL
+	}
 	return result
 }
 
+// canceled returns an error once the context of the runtime is done.
+func canceled(env *object.Environment) *object.Error {
+	if ctx := env.Runtime().Context; ctx != nil && ctx.Err() != nil {
+		return newError("interrupted: %s", ctx.Err())
+	}
+	return nil
+}
+
+// trace reports a statement or call about to be evaluated to the hook
+// of the runtime, if any.
+func trace(node ast.Node, env *object.Environment) {
+	if rt := env.Runtime(); rt.Hook != nil {
+		rt.Hook(node, env, rt.Depth)
+	}
+}
+
 func nativeBooleanVariable(input bool) *object.Boolean {
 	if input {
 		return TRUE
@@ -151,43 +280,54 @@ func evalPrefixExpression(operator string, right object.Object) object.Object {
 	case "-":
 		//要不要处理-----1这种情况
 		return evalMinusOperatorExpression(right)
+	case "~":
+		switch right := right.(type) {
+		case *object.Integer:
+			return &object.Integer{Value: ^right.Value}
+		case *object.BigInteger:
+			return integerObject(new(big.Int).Not(right.Value))
+		}
+		return newError("unknown operator: ~%s", right.Type())
 	default:
 		return newError("unknown operator: %s%s", operator, right.Type())
 	}
 }
 
 func evalBangOperatorExpression(right object.Object) object.Object {
-	switch right {
-	case TRUE:
-		return FALSE
-	case FALSE:
-		return TRUE
-	case NULL:
-		return TRUE
-	default:
-		return FALSE
-	}
+	return nativeBooleanVariable(!isTrue(right))
 }
 
 func evalMinusOperatorExpression(right object.Object) object.Object {
-	if right.Type() != object.INTEGER_OBJ {
+	switch right := right.(type) {
+	case *object.Integer:
+		if right.Value == math.MinInt64 {
+			return integerObject(new(big.Int).Neg(big.NewInt(right.Value)))
+		}
+		return &object.Integer{Value: -right.Value}
+	case *object.BigInteger:
+		return integerObject(new(big.Int).Neg(right.Value))
+	case *object.Float:
+		return &object.Float{Value: -right.Value}
+	default:
 		return newError("unknown operator: -%s", right.Type())
 	}
-
-	value := right.(*object.Integer).Value
-	return &object.Integer{Value: -value}
 }
 
 func evalInfixExpression(left object.Object, operator string, right object.Object) object.Object {
 	switch {
 	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
 		return evalIntegerInfixExpression(left, operator, right)
+	case isNumber(left) && isNumber(right):
+		return evalFloatInfixExpression(toFloat(left), operator, toFloat(right))
 	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
 		return evalStringInfixExpression(left, operator, right)
+	case left.Type() == object.TIME_OBJ && (right.Type() == object.TIME_OBJ || right.Type() == object.INTEGER_OBJ),
+		right.Type() == object.TIME_OBJ && left.Type() == object.INTEGER_OBJ:
+		return evalTimeInfixExpression(left, operator, right)
 	case operator == "==":
-		return nativeBooleanVariable(left == right)
+		return nativeBooleanVariable(objectsEqual(left, right))
 	case operator == "!=":
-		return nativeBooleanVariable(left != right)
+		return nativeBooleanVariable(!objectsEqual(left, right))
 	case left.Type() != right.Type():
 		return newError("type mismatch: %s %s %s",
 			left.Type(), operator, right.Type())
@@ -197,18 +337,41 @@ func evalInfixExpression(left object.Object, operator string, right object.Objec
 	}
 }
 
-func evalIntegerInfixExpression(left object.Object, operator string, right object.Object) object.Object {
-	lval := left.(*object.Integer).Value
-	rval := right.(*object.Integer).Value
+// isNumber reports whether obj is an integer or a float.
+func isNumber(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.FLOAT_OBJ
+}
+
+func toFloat(obj object.Object) float64 {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return float64(obj.Value)
+	case *object.BigInteger:
+		f, _ := new(big.Float).SetInt(obj.Value).Float64()
+		return f
+	}
+	return obj.(*object.Float).Value
+}
+
+// evalFloatInfixExpression evaluates arithmetic on floats, and on an
+// integer and a float, which is done in floating point.
+func evalFloatInfixExpression(lval float64, operator string, rval float64) object.Object {
 	switch operator {
 	case "+":
-		return &object.Integer{Value: lval + rval}
+		return &object.Float{Value: lval + rval}
 	case "-":
-		return &object.Integer{Value: lval - rval}
+		return &object.Float{Value: lval - rval}
 	case "*":
-		return &object.Integer{Value: lval * rval}
+		return &object.Float{Value: lval * rval}
 	case "/":
-		return &object.Integer{Value: lval / rval}
+		return &object.Float{Value: lval / rval}
+	case "%":
+		if rval == 0 {
+			return newError("modulo by zero: %s %% 0.0", (&object.Float{Value: lval}).Inspect())
+		}
+		return &object.Float{Value: math.Mod(lval, rval)}
+	case "**":
+		return &object.Float{Value: math.Pow(lval, rval)}
 	case "<":
 		return nativeBooleanVariable(lval < rval)
 	case ">":
@@ -219,18 +382,91 @@ func evalIntegerInfixExpression(left object.Object, operator string, right objec
 		return nativeBooleanVariable(lval != rval)
 	default:
 		return newError("unknown operator: %s %s %s",
-			left.Type(), operator, right.Type())
+			object.FLOAT_OBJ, operator, object.FLOAT_OBJ)
 	}
 }
 
+// evalIntegerInfixExpression evaluates integer operations in int64, and
+// those whose operands or result do not fit one on big integers.
+func evalIntegerInfixExpression(left object.Object, operator string, right object.Object) object.Object {
+	l, ok1 := left.(*object.Integer)
+	r, ok2 := right.(*object.Integer)
+	if !ok1 || !ok2 {
+		return evalBigIntegerInfixExpression(bigInt(left), operator, bigInt(right))
+	}
+	lval, rval := l.Value, r.Value
+	switch operator {
+	case "+":
+		if sum := lval + rval; (sum > lval) == (rval > 0) {
+			return &object.Integer{Value: sum}
+		}
+	case "-":
+		if diff := lval - rval; (diff < lval) == (rval > 0) {
+			return &object.Integer{Value: diff}
+		}
+	case "*":
+		if product := lval * rval; lval == 0 || product/lval == rval && !(lval == -1 && rval == math.MinInt64) {
+			return &object.Integer{Value: product}
+		}
+	case "/":
+		if rval == 0 {
+			return newError("division by zero: %d / %d", lval, rval)
+		}
+		if lval != math.MinInt64 || rval != -1 {
+			return &object.Integer{Value: lval / rval}
+		}
+	case "%":
+		if rval == 0 {
+			return newError("modulo by zero: %d %% %d", lval, rval)
+		}
+		return &object.Integer{Value: lval % rval}
+	case "&":
+		return &object.Integer{Value: lval & rval}
+	case "|":
+		return &object.Integer{Value: lval | rval}
+	case "^":
+		return &object.Integer{Value: lval ^ rval}
+	case "<<":
+		if rval >= 0 && rval < 63 && lval<<rval>>rval == lval {
+			return &object.Integer{Value: lval << rval}
+		}
+	case ">>":
+		if rval >= 0 {
+			return &object.Integer{Value: lval >> uint64(rval)}
+		}
+	case "<":
+		return nativeBooleanVariable(lval < rval)
+	case ">":
+		return nativeBooleanVariable(lval > rval)
+	case "==":
+		return nativeBooleanVariable(lval == rval)
+	case "!=":
+		return nativeBooleanVariable(lval != rval)
+	}
+	// overflows, powers, negative shifts and unknown operators
+	return evalBigIntegerInfixExpression(big.NewInt(lval), operator, big.NewInt(rval))
+}
+
+// evalStringInfixExpression concatenates and compares strings, which
+// are ordered byte by byte.
 func evalStringInfixExpression(left object.Object, operator string, right object.Object) object.Object {
-	if operator != "+" {
+	leftVal := left.(*object.String).Value
+	rightVal := right.(*object.String).Value
+	switch operator {
+	case "+":
+		return &object.String{Value: leftVal + rightVal}
+	case "<":
+		return nativeBooleanVariable(leftVal < rightVal)
+	case ">":
+		return nativeBooleanVariable(leftVal > rightVal)
+	case "==":
+		return nativeBooleanVariable(leftVal == rightVal)
+	case "!=":
+		return nativeBooleanVariable(leftVal != rightVal)
+	default:
 		return newError("unknown operator: %s %s %s",
 			left.Type(), operator, right.Type())
 	}
-	leftVal := left.(*object.String).Value
-	rightVal := right.(*object.String).Value
-	return &object.String{Value: leftVal + rightVal}
 }
 
 func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Object {
@@ -247,16 +483,65 @@ func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Obje
 	}
 }
 
+func evalConditionalExpression(ce *ast.ConditionalExpression, env *object.Environment) object.Object {
+	condition := Eval(ce.Condition, env)
+	if isError(condition) {
+		return condition
+	}
+	if isTrue(condition) {
+		return Eval(ce.Consequence, env)
+	}
+	return Eval(ce.Alternative, env)
+}
+
+// evalMatchExpression evaluates the body of the first arm that has a
+// pattern equal to the value, as by ==, and a true guard. It is null
+// when no arm matches.
+func evalMatchExpression(me *ast.MatchExpression, env *object.Environment) object.Object {
+	value := Eval(me.Value, env)
+	if isError(value) {
+		return value
+	}
+	for _, arm := range me.Arms {
+		matched := len(arm.Patterns) == 0
+		for _, pattern := range arm.Patterns {
+			p := Eval(pattern, env)
+			if isError(p) {
+				return p
+			}
+			if objectsEqual(value, p) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+		if arm.Guard != nil {
+			guard := Eval(arm.Guard, env)
+			if isError(guard) {
+				return guard
+			}
+			if !isTrue(guard) {
+				continue
+			}
+		}
+		return Eval(arm.Body, env)
+	}
+	return NULL
+}
+
+// isTrue is the truthiness of a value, used by conditions, `!` and
+// `bool`: false and null are false, and every other value is true,
+// including 0, "" and empty arrays and hashes.
 func isTrue(obj object.Object) bool {
-	switch obj {
-	case TRUE:
-		return true
-	case FALSE:
-		return false
-	case NULL:
+	switch obj := obj.(type) {
+	case *object.Boolean:
+		return obj.Value
+	case *object.Null:
 		return false
 	default:
-		return true //why default true?
+		return true
 	}
 }
 
@@ -283,20 +568,48 @@ func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Ob
 	return result
 }
 
-func callFunction(fn object.Object, args []object.Object) object.Object {
+// callFunction applies fn to args; env is the environment of the call,
+// which builtins are given.
+func callFunction(fn object.Object, args []object.Object, env *object.Environment) object.Object {
 	switch fn := fn.(type) {
 	case *object.Function:
+		if len(args) < len(fn.Parameters) {
+			return newError("wrong number of arguments. got=%d, want=%d",
+				len(args), len(fn.Parameters))
+		}
 		extendEnv := extendFunctionEnv(fn, args)
+		rt := extendEnv.Runtime()
+		rt.Depth++
+		defer func() { rt.Depth-- }()
 		evaluated := Eval(fn.Body, extendEnv)
 		return unwrapReturnValue(evaluated)
 
 	case *object.Builtin:
-		return fn.Fn(args...)
+		if err := checkArity(fn, len(args)); err != nil {
+			return err
+		}
+		return fn.Fn(env, args...)
 	default:
 		return newError("not a function: %s", fn.Type())
 	}
 }
 
+func checkArity(fn *object.Builtin, got int) *object.Error {
+	switch {
+	case fn.MaxArgs < 0 && got < fn.MinArgs:
+		return newError("wrong number of arguments. got=%d, want at least %d",
+			got, fn.MinArgs)
+	case fn.MaxArgs >= 0 && (got < fn.MinArgs || got > fn.MaxArgs):
+		if fn.MinArgs == fn.MaxArgs {
+			return newError("wrong number of arguments. got=%d, want=%d",
+				got, fn.MinArgs)
+		}
+		return newError("wrong number of arguments. got=%d, want=%d to %d",
+			got, fn.MinArgs, fn.MaxArgs)
+	}
+	return nil
+}
+
 func extendFunctionEnv(function *object.Function, args []object.Object) *object.Environment {
 	env := object.NewEnclosedEnvironment(function.Env)
 	for paramIdx, param := range function.Parameters {
@@ -305,13 +618,34 @@ func extendFunctionEnv(function *object.Function, args []object.Object) *object.
 	return env
 }
 
+// unwrapReturnValue gives the value a function call evaluates to: the
+// returned one, and NULL rather than nil for a body without a value.
 func unwrapReturnValue(obj object.Object) object.Object {
 	if returnValue, ok := obj.(*object.ReturnValue); ok {
-		return returnValue.Value
+		obj = returnValue.Value
+	}
+	if obj == nil {
+		return NULL
 	}
 	return obj
 }
 
+// GetBuiltin returns the builtin bound to name, if any.
+func GetBuiltin(name string) (*object.Builtin, bool) {
+	builtin, ok := builtins[name]
+	return builtin, ok
+}
+
+// BuiltinNames returns the names of all builtins in sorted order.
+func BuiltinNames() []string {
+	names := make([]string, 0, len(builtins))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
 	if val, ok := env.Get(node.Value); ok {
 		return val
@@ -321,3 +655,164 @@ func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object
 	}
 	return newError("identifier not found: " + node.Value)
 }
+
+func evalIndexExpression(left, index object.Object) object.Object {
+	switch {
+	case left.Type() == object.MODULE_OBJ && index.Type() == object.STRING_OBJ:
+		return evalMemberExpression(left, index.(*object.String).Value)
+	case left.Type() == object.HASH_OBJ:
+		key, ok := index.(object.Hashable)
+		if !ok {
+			return newError("unusable as hash key: %s", index.Type())
+		}
+		if val, ok := left.(*object.Hash).Get(key); ok {
+			return val
+		}
+		return NULL
+	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
+		elements := left.(*object.Array).Elements
+		if i, ok := elementIndex(index, len(elements)); ok {
+			return elements[i]
+		}
+		return NULL
+	case left.Type() == object.STRING_OBJ && index.Type() == object.INTEGER_OBJ:
+		runes := []rune(left.(*object.String).Value)
+		if i, ok := elementIndex(index, len(runes)); ok {
+			return &object.String{Value: string(runes[i])}
+		}
+		return NULL
+	case left.Type() == object.ARRAY_OBJ || left.Type() == object.STRING_OBJ:
+		return newError("index operator not supported: %s[%s]", left.Type(), index.Type())
+	default:
+		return newError("index operator not supported: %s", left.Type())
+	}
+}
+
+func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
+	hash := object.NewHash()
+	for i, keyNode := range node.Keys {
+		key := Eval(keyNode, env)
+		if isError(key) {
+			return key
+		}
+		hashKey, ok := key.(object.Hashable)
+		if !ok {
+			return newError("unusable as hash key: %s", key.Type())
+		}
+		value := Eval(node.Values[i], env)
+		if isError(value) {
+			return value
+		}
+		hash.Set(hashKey, value)
+	}
+	return hash
+}
+
+// elementIndex turns an integer index, which counts from the end when
+// negative, into a position in a sequence of length n, and reports
+// whether it is in range. Big integers never are.
+func elementIndex(obj object.Object, n int) (int, bool) {
+	i, ok := obj.(*object.Integer)
+	if !ok {
+		return 0, false
+	}
+	index := i.Value
+	if index < 0 {
+		index += int64(n)
+	}
+	if index < 0 || index >= int64(n) {
+		return 0, false
+	}
+	return int(index), true
+}
+
+// sliceBound turns a slice bound, which counts from the end when
+// negative, into a position clamped to a sequence of length n.
+func sliceBound(bound object.Object, n int, omitted int) (int, *object.Error) {
+	if bound == nil {
+		return omitted, nil
+	}
+	if b, ok := bound.(*object.BigInteger); ok {
+		if b.Value.Sign() < 0 {
+			return 0, nil
+		}
+		return n, nil
+	}
+	i, ok := bound.(*object.Integer)
+	if !ok {
+		return 0, newError("slice bound must be INTEGER, got %s", bound.Type())
+	}
+	index := i.Value
+	if index < 0 {
+		index += int64(n)
+	}
+	if index < 0 {
+		return 0, nil
+	}
+	if index > int64(n) {
+		return n, nil
+	}
+	return int(index), nil
+}
+
+// evalSliceExpression returns the elements of an array, or characters of
+// a string, from the low bound up to the high bound.
+func evalSliceExpression(se *ast.SliceExpression, env *object.Environment) object.Object {
+	left := Eval(se.Left, env)
+	if isError(left) || se.Optional && left == NULL {
+		return left
+	}
+	var low, high object.Object
+	if se.Low != nil {
+		if low = Eval(se.Low, env); isError(low) {
+			return low
+		}
+	}
+	if se.High != nil {
+		if high = Eval(se.High, env); isError(high) {
+			return high
+		}
+	}
+
+	var n int
+	switch left := left.(type) {
+	case *object.Array:
+		n = len(left.Elements)
+	case *object.String:
+		n = utf8.RuneCountInString(left.Value)
+	default:
+		return newError("slice operator not supported: %s", left.Type())
+	}
+	lo, err := sliceBound(low, n, 0)
+	if err != nil {
+		return err
+	}
+	hi, err := sliceBound(high, n, n)
+	if err != nil {
+		return err
+	}
+	if hi < lo {
+		hi = lo
+	}
+
+	if array, ok := left.(*object.Array); ok {
+		elements := make([]object.Object, hi-lo)
+		copy(elements, array.Elements[lo:hi])
+		return &object.Array{Elements: elements}
+	}
+	return &object.String{Value: string([]rune(left.(*object.String).Value)[lo:hi])}
+}
+
+func evalMemberExpression(obj object.Object, name string) object.Object {
+	if re, ok := obj.(*object.Regex); ok {
+		return regexMethod(re, name)
+	}
+	module, ok := obj.(*object.Module)
+	if !ok {
+		return newError("member access not supported: %s", obj.Type())
+	}
+	if val, ok := module.Exports[name]; ok {
+		return val
+	}
+	return newError("module %s has no exported member %s", module.Path, name)
+}
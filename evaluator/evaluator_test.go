@@ -1,6 +1,7 @@
 package evaluator
 
 import (
+	"interpreter/ast"
 	"interpreter/lexer"
 	"interpreter/object"
 	"interpreter/parser"
@@ -27,6 +28,26 @@ func TestEvalIntegerExpression(t *testing.T) {
 		{"3 * 3 * 3 + 10", 37},
 		{"3 * (3 * 3) + 10", 37},
 		{"(5 + 10 * 2 + 15 / 3) * 2 + -10", 50},
+		{"7 % 3", 1},
+		{"-7 % 3", -1},
+		{"7 % -3", 1},
+		{"1 + 7 % 4 * 2", 7},
+		{"2 ** 10", 1024},
+		{"2 ** 3 ** 2", 512},
+		{"-2 ** 2", -4},
+		{"(-2) ** 3", -8},
+		{"2 * 3 ** 2", 18},
+		{"5 ** 0", 1},
+		{"6 & 3", 2},
+		{"6 | 3", 7},
+		{"6 ^ 3", 5},
+		{"~5", -6},
+		{"~-1", 0},
+		{"1 << 4", 16},
+		{"-16 >> 2", -4},
+		{"1 + 1 << 2", 8},
+		{"(6 & 3) + (2 ^ 1)", 5},
+		{"1 | 2 ^ 3 & 5", 3},
 	}
 	for _, tt := range tests {
 		evaluated := testEval(tt.input)
@@ -34,6 +55,43 @@ func TestEvalIntegerExpression(t *testing.T) {
 	}
 }
 
+func TestEvalFloatExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"2.5", 2.5},
+		{"-2.5", -2.5},
+		{"1.5 + 1.5", 3},
+		{"1 + 0.5", 1.5},
+		{"0.5 * 4", 2},
+		{"1 / 4.0", 0.25},
+		{"10 - 2.5 * 2", 5},
+		{"7.5 % 2", 1.5},
+		{"-7.5 % 2", -1.5},
+		{"2 ** 0.5 ** 2", 1.189207115002721},
+		{"4 ** 0.5", 2},
+		{"2 ** -2", 0.25},
+	}
+	for _, tt := range tests {
+		testFloatObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func testFloatObject(t *testing.T, obj object.Object, expected float64) bool {
+	result, ok := obj.(*object.Float)
+	if !ok {
+		t.Errorf("object is not Float. got=%T (%+v)", obj, obj)
+		return false
+	}
+	if result.Value != expected {
+		t.Errorf("object has wrong value. got=%g, want=%g",
+			result.Value, expected)
+		return false
+	}
+	return true
+}
+
 func testEval(input string) object.Object {
 	l := lexer.New(input)
 	p := parser.New(l)
@@ -80,6 +138,10 @@ func TestEvalBooleanExpression(t *testing.T) {
 		{"(1 < 2) == false", false},
 		{"(1 > 2) == true", false},
 		{"(1 > 2) == false", true},
+		{"1.5 < 2", true},
+		{"2 > 1.5", true},
+		{"2 == 2.0", true},
+		{"0.5 != 0.5", false},
 	}
 	for _, tt := range tests {
 		evaluated := testEval(tt.input)
@@ -143,6 +205,71 @@ func TestIfElseExpressions(t *testing.T) {
 	}
 }
 
+func TestConditionalExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`true ? 1 : 2`, 1},
+		{`false ? 1 : 2`, 2},
+		{`null ? 1 : 2`, 2},
+		{`0 ? "zero" : "none"`, "zero"},
+		{`"" ? 1 : 2`, 1},
+		{`let n = 5; n < 0 ? "neg" : n == 0 ? "zero" : "pos"`, "pos"},
+		{`let n = 0; n < 0 ? "neg" : n == 0 ? "zero" : "pos"`, "zero"},
+		{`true ? 1 : undefined`, 1},
+		{`false ? undefined : 2`, 2},
+		{`null ?? false ? 1 : 2`, 2},
+		{`1 + (true ? 2 : 3) * 2`, 5},
+		{`undefined ? 1 : 2`, "identifier not found: undefined"},
+		{`let f = fn(x) { return x ? x : 0; }; f(null)`, 0},
+	}
+	for _, tt := range tests {
+		testValue(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestMatchExpressions(t *testing.T) {
+	grade := `let grade = fn(n) {
+		match (n / 10) {
+			10 | 9 => "A",
+			8 => "B",
+			7 if n % 10 > 4 => "C+",
+			7 => "C",
+			_ => "F",
+		}
+	};`
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{grade + `grade(100)`, "A"},
+		{grade + `grade(93)`, "A"},
+		{grade + `grade(80)`, "B"},
+		{grade + `grade(75)`, "C+"},
+		{grade + `grade(71)`, "C"},
+		{grade + `grade(12)`, "F"},
+		{`match ("b") { "a" => 1, "b" => 2 }`, 2},
+		{`match ("c") { "a" => 1, "b" => 2 }`, nil},
+		{`match (1) {}`, nil},
+		{`match (-3) { -3 => "minus three", _ => "other" }`, "minus three"},
+		{`match (2.0) { 2 => "two" }`, "two"},
+		{`match (null) { false => 1, null => 2 }`, 2},
+		{`match ([1]) { 1 => "one", _ => "other" }`, "other"},
+		{`match (1) { 1 if false => "a", 1 if null => "b", 1 if 0 => "c" }`, "c"},
+		{`match (1) { _ if false => "a", _ => "b" }`, "b"},
+		{`match (1) { 1 => "a", 1 => undefined }`, "a"},
+		{`match (2) { 1 if undefined => "a", _ => "b" }`, "b"},
+		{`match (undefined) { _ => 1 }`, "identifier not found: undefined"},
+		{`match (1) { 1 if undefined => "a" }`, "identifier not found: undefined"},
+		{`let f = fn(x) { match (x) { 1 => if (true) { return "early"; }, _ => "late" }; "end" }; f(1)`, "early"},
+		{`let f = fn(x) { match (x) { 1 => if (true) { return "early"; }, _ => "late" }; "end" }; f(2)`, "end"},
+	}
+	for _, tt := range tests {
+		testValue(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
 func testNullObject(t *testing.T, obj object.Object) bool {
 	if obj != NULL {
 		t.Errorf("object is not NULL. got=%T (%+v)", obj, obj)
@@ -221,6 +348,16 @@ func TestErrorHandling(t *testing.T) {
 			`"Hello" - "World"`,
 			"unknown operator: STRING - STRING",
 		},
+		{"6 & 3 == 2", "type mismatch: INTEGER & BOOLEAN"},
+		{"7 % 0", "modulo by zero: 7 % 0"},
+		{"7.5 % 0", "modulo by zero: 7.5 % 0.0"},
+		{"7 / 0", "division by zero: 7 / 0"},
+		{"1 << -1", "negative shift count: 1 << -1"},
+		{"8 >> -2", "negative shift count: 8 >> -2"},
+		{"1.5 & 1", "unknown operator: FLOAT & FLOAT"},
+		{"~1.5", "unknown operator: ~FLOAT"},
+		{`~"a"`, "unknown operator: ~STRING"},
+		{`true | false`, "unknown operator: BOOLEAN | BOOLEAN"},
 	}
 	for _, tt := range tests {
 		evaluated := testEval(tt.input)
@@ -353,3 +490,120 @@ func TestBuiltinFunctions(t *testing.T) {
 		}
 	}
 }
+
+func TestErrorPosition(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string //node the error is raised at
+		line     int
+		column   int
+	}{
+		{"let a = 1;\nlet b = a + true;", "(a + true)", 2, 11},
+		{"foobar", "foobar", 1, 1},
+		{"let f = fn(x) {\n  -x\n};\nf(true)", "(-x)", 2, 3},
+		{`len(1, 2) + 1`, "len(1, 2)", 1, 4},
+	}
+	for _, tt := range tests {
+		errObj, ok := testEval(tt.input).(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned for %q", tt.input)
+			continue
+		}
+		if errObj.Node == nil || errObj.Node.String() != tt.expected {
+			t.Errorf("error raised at wrong node. expected=%q, got=%v", tt.expected, errObj.Node)
+			continue
+		}
+		tok := ast.NodeToken(errObj.Node)
+		if tok.Line != tt.line || tok.Column != tt.column {
+			t.Errorf("wrong position. expected=%d:%d, got=%d:%d", tt.line, tt.column, tok.Line, tok.Column)
+		}
+	}
+}
+
+func TestHashes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`{"one": 1, "two": 2}["two"]`, 2},
+		{`{"one": 1}["three"]`, nil},
+		{`let key = "k"; {key + "1": 5}["k1"]`, 5},
+		{`{1: "int", true: "bool", "1": "string"}[1]`, "int"},
+		{`{1: "int", true: "bool", "1": "string"}[true]`, "bool"},
+		{`{1: "int", true: "bool", "1": "string"}["1"]`, "string"},
+		{`{"a": 1, "a": 2}["a"]`, 2},
+		{`len({"a": 1, "b": 2, "a": 3})`, 2},
+		{`{"a": 1}[fn(x) { x }]`, "unusable as hash key: FUNCTION"},
+		{`{[1]: 1}`, "unusable as hash key: ARRAY"},
+		{`{"a": foo}`, "identifier not found: foo"},
+	}
+	for _, tt := range tests {
+		testValue(t, tt.input, testEval(tt.input), tt.expected)
+	}
+
+	expected := `{"b": 1, "a": [true], 3: {}}`
+	if got := testEval(`{"b": 1, "a": [true], 3: {}, "b": 1}`).Inspect(); got != expected {
+		t.Errorf("wrong Inspect. expected=%q, got=%q", expected, got)
+	}
+}
+
+func TestNull(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`null`, nil},
+		{`let x = null; x`, nil},
+		{`null == null`, true},
+		{`null != null`, false},
+		{`null == false`, false},
+		{`0 == null`, false},
+		{`"" != null`, true},
+		{`[][0] == null`, true},
+		{`if (1 > 2) { 1 } == null`, true},
+		{`!null`, true},
+		{`null + 1`, "type mismatch: NULL + INTEGER"},
+		{`null < null`, "unknown operator: NULL < NULL"},
+		{`null ?? 5`, 5},
+		{`0 ?? 5`, 0},
+		{`false ?? 5`, false},
+		{`null ?? null ?? 7`, 7},
+		{`1 ?? undefined`, 1},
+		{`null ?? undefined`, "identifier not found: undefined"},
+		{`{"a": 1}["b"] ?? 2`, 2},
+		{`let f = null; f?.(undefined)`, nil},
+		{`let f = fn(x) { x * 2 }; f?.(4)`, 8},
+		{`let h = null; h?.["k"]`, nil},
+		{`let h = {"k": 3}; h?.["k"]`, 3},
+		{`let h = {"k": null}; h?.["k"]?.["j"] ?? "none"`, "none"},
+		{`let s = null; s?.[1:]`, nil},
+		{`let m = null; m?.x`, nil},
+		{`let f = 1; f?.()`, "not a function: INTEGER"},
+		{`let h = null; h["k"]`, "index operator not supported: NULL"},
+	}
+	for _, tt := range tests {
+		testValue(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestEmptyBodiesAreNull(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`let f = fn() {}; f()`, nil},
+		{`let f = fn() { let x = 1; }; f()`, nil},
+		{`let f = fn() {}; f() ?? 1`, 1},
+		{`let f = fn() {}; f() == null`, true},
+		{`let f = fn() {}; is_null(f())`, true},
+		{`let f = fn() {}; match (f()) { null => "null", 1 => "one" }`, "null"},
+		{`let f = fn() {}; [f()]`, []interface{}{nil}},
+		{`let f = fn() {}; json_stringify({"a": f()})`, `{"a":null}`},
+		{`let f = fn() {}; f()?.x ?? 2`, 2},
+		{`if (true) {} == null`, true},
+		{`if (true) { let x = 1; } == null`, true},
+	}
+	for _, tt := range tests {
+		testValue(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
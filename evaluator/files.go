@@ -0,0 +1,177 @@
+package evaluator
+
+import (
+	"errors"
+	"interpreter/object"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// filePath resolves the path argument of the file builtin name according
+// to the runtime: paths outside its FileRoot, once symbolic links are
+// followed, are rejected.
+func filePath(name string, env *object.Environment, arg object.Object) (string, *object.Error) {
+	path, err := stringArg(name, arg)
+	if err != nil {
+		return "", err
+	}
+	rt := env.Runtime()
+	if rt.FilesDisabled {
+		return "", newError("file access is disabled: %s", name)
+	}
+	if rt.FileRoot == "" {
+		return path, nil
+	}
+	root, rootErr := filepath.Abs(rt.FileRoot)
+	if rootErr != nil {
+		return "", newError("%s: %s", name, rootErr)
+	}
+	resolved := filepath.Clean(path)
+	if !filepath.IsAbs(resolved) {
+		resolved = filepath.Join(root, resolved)
+	}
+	if !within(root, resolved) || !within(evalSymlinks(root), evalSymlinks(resolved)) {
+		return "", newError("%s: path %s is outside the root directory", name, path)
+	}
+	return resolved, nil
+}
+
+// within reports whether path is dir or below it.
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// MAX_SYMLINKS is how many dangling symbolic links evalSymlinks follows
+// before it gives up on a path.
+const MAX_SYMLINKS = 255
+
+// evalSymlinks follows the symbolic links in path, including dangling ones
+// that creating the file would follow, so that a link cannot lead out of
+// the root. It returns "" for a path whose links cannot be followed.
+func evalSymlinks(path string) string {
+	var missing []string
+	for links := 0; ; {
+		resolved, err := filepath.EvalSymlinks(path)
+		if err == nil {
+			return filepath.Join(append([]string{resolved}, missing...)...)
+		}
+		if info, lstatErr := os.Lstat(path); lstatErr == nil && info.Mode()&os.ModeSymlink != 0 {
+			target, readErr := os.Readlink(path)
+			if links++; readErr != nil || links > MAX_SYMLINKS {
+				return ""
+			}
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(filepath.Dir(path), target)
+			}
+			path = filepath.Clean(target)
+			continue
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		missing = append([]string{filepath.Base(path)}, missing...)
+		path = parent
+	}
+}
+
+// fileError reports the failure of an operation on the path given to the
+// file builtin name, without the root it was resolved against.
+func fileError(name string, path object.Object, err error) *object.Error {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		err = pathErr.Err
+	}
+	return newError("%s: %s: %s", name, path.(*object.String).Value, err)
+}
+
+func readFile(env *object.Environment, args ...object.Object) object.Object {
+	path, err := filePath("read_file", env, args[0])
+	if err != nil {
+		return err
+	}
+	content, readErr := os.ReadFile(path)
+	if readErr != nil {
+		return fileError("read_file", args[0], readErr)
+	}
+	return &object.String{Value: string(content)}
+}
+
+func writeFile(env *object.Environment, args ...object.Object) object.Object {
+	return putFile("write_file", os.O_TRUNC, env, args)
+}
+
+func appendFile(env *object.Environment, args ...object.Object) object.Object {
+	return putFile("append_file", os.O_APPEND, env, args)
+}
+
+// putFile writes the content argument to the file at the path argument,
+// creating it if needed; flag says what happens to an existing file.
+func putFile(name string, flag int, env *object.Environment, args []object.Object) object.Object {
+	path, err := filePath(name, env, args[0])
+	if err != nil {
+		return err
+	}
+	content, err := stringArg(name, args[1])
+	if err != nil {
+		return err
+	}
+	f, openErr := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|flag, 0644)
+	if openErr != nil {
+		return fileError(name, args[0], openErr)
+	}
+	_, writeErr := f.WriteString(content)
+	if closeErr := f.Close(); writeErr == nil {
+		writeErr = closeErr
+	}
+	if writeErr != nil {
+		return fileError(name, args[0], writeErr)
+	}
+	return NULL
+}
+
+// listDir returns the names of the entries of a directory, sorted.
+func listDir(env *object.Environment, args ...object.Object) object.Object {
+	path, err := filePath("list_dir", env, args[0])
+	if err != nil {
+		return err
+	}
+	entries, readErr := os.ReadDir(path)
+	if readErr != nil {
+		return fileError("list_dir", args[0], readErr)
+	}
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.Name()
+	}
+	return stringArray(names)
+}
+
+func exists(env *object.Environment, args ...object.Object) object.Object {
+	path, err := filePath("exists", env, args[0])
+	if err != nil {
+		return err
+	}
+	_, statErr := os.Stat(path)
+	return nativeBooleanVariable(statErr == nil)
+}
+
+// remove removes a file or an empty directory, but not the root.
+func remove(env *object.Environment, args ...object.Object) object.Object {
+	path, err := filePath("remove", env, args[0])
+	if err != nil {
+		return err
+	}
+	if root := env.Runtime().FileRoot; root != "" {
+		if root, _ = filepath.Abs(root); evalSymlinks(path) == evalSymlinks(root) {
+			return newError("remove: cannot remove the root directory")
+		}
+	}
+	if removeErr := os.Remove(path); removeErr != nil {
+		return fileError("remove", args[0], removeErr)
+	}
+	return NULL
+}
@@ -0,0 +1,97 @@
+package evaluator
+
+import (
+	"interpreter/lexer"
+	"interpreter/object"
+	"interpreter/parser"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// evalFiles evaluates input with the file builtins confined to root.
+func evalFiles(input, root string) object.Object {
+	env := object.NewEnvironment()
+	env.Runtime().FileRoot = root
+	return Eval(parser.New(lexer.New(input)).ParseProgram(), env)
+}
+
+func TestFileBuiltins(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	os.WriteFile(filepath.Join(outside, "secret"), []byte("s"), 0644)
+	os.Mkdir(filepath.Join(root, "sub"), 0755)
+	if err := os.Symlink(outside, filepath.Join(root, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`write_file("a.txt", "one")`, nil},
+		{`append_file("a.txt", ", two"); read_file("a.txt")`, "one, two"},
+		{`write_file("a.txt", "new"); read_file("a.txt")`, "new"},
+		{`append_file("sub/b.txt", "b"); read_file("sub/../sub/b.txt")`, "b"},
+		{`exists("a.txt")`, true},
+		{`exists("missing")`, false},
+		{`list_dir(".")`, []string{"a.txt", "link", "sub"}},
+		{`list_dir("sub")`, []string{"b.txt"}},
+		{`remove("sub/b.txt"); list_dir("sub")`, []string{}},
+		{`read_file("missing")`, "read_file: missing: no such file or directory"},
+		{`remove("sub"); exists("sub")`, false},
+		{`remove(".")`, "remove: cannot remove the root directory"},
+		{`read_file("../x")`, "read_file: path ../x is outside the root directory"},
+		{`exists("sub/../../x")`, "exists: path sub/../../x is outside the root directory"},
+		{`read_file("` + filepath.Join(outside, "secret") + `")`,
+			"read_file: path " + filepath.Join(outside, "secret") + " is outside the root directory"},
+		{`read_file("link/secret")`, "read_file: path link/secret is outside the root directory"},
+		{`write_file("link/new", "x")`, "write_file: path link/new is outside the root directory"},
+		{`read_file("` + filepath.Join(root, "a.txt") + `")`, "new"},
+		{`write_file("a.txt", 1)`, "argument to `write_file` not supported, got INTEGER"},
+		{`list_dir(1)`, "argument to `list_dir` not supported, got INTEGER"},
+	}
+	for _, tt := range tests {
+		testValue(t, tt.input, evalFiles(tt.input, root), tt.expected)
+	}
+}
+
+func TestFileBuiltinsDanglingLinks(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	links := map[string]string{
+		"out":    filepath.Join(outside, "pwned"),
+		"outdir": filepath.Join(outside, "missing", "dir"),
+		"in":     "target.txt",
+		"loop":   "loop",
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(root, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`write_file("out", "x")`, "write_file: path out is outside the root directory"},
+		{`append_file("outdir/x", "x")`, "append_file: path outdir/x is outside the root directory"},
+		{`write_file("loop", "x")`, "write_file: path loop is outside the root directory"},
+		{`write_file("in", "x"); read_file("target.txt")`, "x"},
+	}
+	for _, tt := range tests {
+		testValue(t, tt.input, evalFiles(tt.input, root), tt.expected)
+	}
+	if _, err := os.Lstat(filepath.Join(outside, "pwned")); !os.IsNotExist(err) {
+		t.Errorf("file created outside the root: %v", err)
+	}
+}
+
+func TestFileBuiltinsDisabled(t *testing.T) {
+	env := object.NewEnvironment()
+	env.Runtime().FilesDisabled = true
+	input := `read_file("a.txt")`
+	evaluated := Eval(parser.New(lexer.New(input)).ParseProgram(), env)
+	testValue(t, input, evaluated, "file access is disabled: read_file")
+}
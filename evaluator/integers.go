@@ -0,0 +1,152 @@
+package evaluator
+
+import (
+	"interpreter/object"
+	"math"
+	"math/big"
+)
+
+// MAX_INTEGER_BITS is the size of the largest integer a result may be.
+const MAX_INTEGER_BITS = 1 << 20
+
+// integerObject returns i as an Integer when it fits an int64, and as a
+// BigInteger otherwise.
+func integerObject(i *big.Int) object.Object {
+	if i.IsInt64() {
+		return &object.Integer{Value: i.Int64()}
+	}
+	if i.BitLen() > MAX_INTEGER_BITS {
+		return newError("integer too large: more than %d bits", MAX_INTEGER_BITS)
+	}
+	return &object.BigInteger{Value: i}
+}
+
+// bigInt returns the value of an Integer or a BigInteger. The result of
+// a BigInteger is shared, and must not be modified.
+func bigInt(obj object.Object) *big.Int {
+	if i, ok := obj.(*object.Integer); ok {
+		return big.NewInt(i.Value)
+	}
+	return obj.(*object.BigInteger).Value
+}
+
+// integerFromFloat truncates f towards zero, ok is false for NaN and
+// infinities.
+func integerFromFloat(f float64) (object.Object, bool) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, false
+	}
+	if f >= math.MinInt64 && f < math.MaxInt64 {
+		return &object.Integer{Value: int64(f)}, true
+	}
+	i, _ := big.NewFloat(f).Int(nil)
+	return integerObject(i), true
+}
+
+// compareIntegers returns -1, 0 or 1 as a is less than, equal to or
+// greater than b.
+func compareIntegers(a, b object.Object) int {
+	ai, ok1 := a.(*object.Integer)
+	bi, ok2 := b.(*object.Integer)
+	switch {
+	case ok1 && ok2 && ai.Value < bi.Value:
+		return -1
+	case ok1 && ok2:
+		if ai.Value > bi.Value {
+			return 1
+		}
+		return 0
+	}
+	return bigInt(a).Cmp(bigInt(b))
+}
+
+// numberLess orders integers exactly, whatever their size, and other
+// numbers in floating point.
+func numberLess(a, b object.Object) bool {
+	if a.Type() == object.INTEGER_OBJ && b.Type() == object.INTEGER_OBJ {
+		return compareIntegers(a, b) < 0
+	}
+	return toFloat(a) < toFloat(b)
+}
+
+// evalBigIntegerInfixExpression evaluates integer operations whose
+// operands or result do not fit an int64.
+func evalBigIntegerInfixExpression(lval *big.Int, operator string, rval *big.Int) object.Object {
+	switch operator {
+	case "+":
+		return integerObject(new(big.Int).Add(lval, rval))
+	case "-":
+		return integerObject(new(big.Int).Sub(lval, rval))
+	case "*":
+		return integerObject(new(big.Int).Mul(lval, rval))
+	case "/":
+		if rval.Sign() == 0 {
+			return newError("division by zero: %s / %s", lval, rval)
+		}
+		return integerObject(new(big.Int).Quo(lval, rval))
+	case "%":
+		if rval.Sign() == 0 {
+			return newError("modulo by zero: %s %% %s", lval, rval)
+		}
+		return integerObject(new(big.Int).Rem(lval, rval))
+	case "**":
+		return bigPow(lval, rval)
+	case "&":
+		return integerObject(new(big.Int).And(lval, rval))
+	case "|":
+		return integerObject(new(big.Int).Or(lval, rval))
+	case "^":
+		return integerObject(new(big.Int).Xor(lval, rval))
+	case "<<", ">>":
+		return bigShift(lval, operator, rval)
+	case "<":
+		return nativeBooleanVariable(lval.Cmp(rval) < 0)
+	case ">":
+		return nativeBooleanVariable(lval.Cmp(rval) > 0)
+	case "==":
+		return nativeBooleanVariable(lval.Cmp(rval) == 0)
+	case "!=":
+		return nativeBooleanVariable(lval.Cmp(rval) != 0)
+	default:
+		return newError("unknown operator: %s %s %s",
+			object.INTEGER_OBJ, operator, object.INTEGER_OBJ)
+	}
+}
+
+// bigPow raises base to an integer power, which gives a float when it is
+// negative.
+func bigPow(base, exp *big.Int) object.Object {
+	if exp.Sign() < 0 && base.Sign() == 0 {
+		return newError("division by zero: %s ** %s", base, exp)
+	}
+	if exp.Sign() < 0 {
+		b, _ := new(big.Float).SetInt(base).Float64()
+		e, _ := new(big.Float).SetInt(exp).Float64()
+		return &object.Float{Value: math.Pow(b, e)}
+	}
+	// 0, 1 and -1 stay small whatever the power
+	if base.CmpAbs(big.NewInt(1)) > 0 &&
+		(!exp.IsInt64() || exp.Int64() > MAX_INTEGER_BITS || int64(base.BitLen()-1)*exp.Int64() > MAX_INTEGER_BITS) {
+		return newError("integer too large: %s ** %s has more than %d bits", base, exp, MAX_INTEGER_BITS)
+	}
+	return integerObject(new(big.Int).Exp(base, exp, nil))
+}
+
+func bigShift(lval *big.Int, operator string, rval *big.Int) object.Object {
+	if rval.Sign() < 0 {
+		return newError("negative shift count: %s %s %s", lval, operator, rval)
+	}
+	if !rval.IsInt64() || rval.Int64() > MAX_INTEGER_BITS {
+		switch {
+		case operator == ">>" && lval.Sign() < 0:
+			return &object.Integer{Value: -1}
+		case operator == ">>" || lval.Sign() == 0:
+			return &object.Integer{Value: 0}
+		}
+		return newError("integer too large: %s << %s has more than %d bits", lval, rval, MAX_INTEGER_BITS)
+	}
+	if operator == "<<" {
+		return integerObject(new(big.Int).Lsh(lval, uint(rval.Int64())))
+	}
+	return integerObject(new(big.Int).Rsh(lval, uint(rval.Int64())))
+}
@@ -0,0 +1,101 @@
+package evaluator
+
+import (
+	"interpreter/object"
+	"testing"
+)
+
+func TestBigIntegers(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`9223372036854775807 + 1`, "9223372036854775808"},
+		{`-9223372036854775807 - 2`, "-9223372036854775809"},
+		{`9223372036854775807 * 3`, "27670116110564327421"},
+		{`-9223372036854775807 - 1`, "-9223372036854775808"},
+		{`-(-9223372036854775807 - 1)`, "9223372036854775808"},
+		{`(-9223372036854775807 - 1) / -1`, "9223372036854775808"},
+		{`(-9223372036854775807 - 1) * -1`, "9223372036854775808"},
+		{`2 ** 64`, "18446744073709551616"},
+		{`2 ** 100 / 2 ** 99`, "2"},
+		{`1 << 64`, "18446744073709551616"},
+		{`-1 << 63`, "-9223372036854775808"},
+		{`(1 << 100) >> 99`, "2"},
+		{`(1 << 100) >> 10000000000000000000`, "0"},
+		{`-(1 << 100) >> 10000000000000000000`, "-1"},
+		{`99999999999999999999`, "99999999999999999999"},
+		{`99999999999999999999 - 99999999999999999998`, "1"},
+		{`99999999999999999999 % 10`, "9"},
+		{`-99999999999999999999 % 10`, "-9"},
+		{`~99999999999999999999`, "-100000000000000000000"},
+		{`(1 << 70) | 1`, "1180591620717411303425"},
+		{`((1 << 70) | 1) & 3`, "1"},
+		{`((1 << 70) ^ (1 << 70))`, "0"},
+		{`99999999999999999999 + 0.5`, "1e+20"},
+		{`2 ** -1`, "0.5"},
+		{`99999999999999999999 > 9223372036854775807`, "true"},
+		{`-99999999999999999999 < -9223372036854775807`, "true"},
+		{`99999999999999999999 == 99999999999999999999`, "true"},
+		{`99999999999999999999 == 99999999999999999998`, "false"},
+		{`[2 ** 64] == [1 << 64]`, "true"},
+		{`{2 ** 64: "big"}[1 << 64]`, "big"},
+		{`len({2 ** 64: "big", -1300789964862373523: "small"})`, "2"},
+		{`{2 ** 64: "big", -1300789964862373523: "small"}[2 ** 64]`, "big"},
+		{`[1, 2][2 ** 64]`, "null"},
+		{`[1, 2][-(2 ** 64):2 ** 64]`, "[1, 2]"},
+		{`sort([2 ** 64, 1, -(2 ** 64)])`, "[-18446744073709551616, 1, 18446744073709551616]"},
+		{`sum([9223372036854775807, 9223372036854775807])`, "18446744073709551614"},
+		{`type(2 ** 64)`, "INTEGER"},
+		{`is_int(2 ** 64)`, "true"},
+		{`int("123456789012345678901234567890")`, "123456789012345678901234567890"},
+		{`int(10000000000000000000.0)`, "10000000000000000000"},
+		{`float(2 ** 64)`, "1.8446744073709552e+19"},
+		{`strings.format("%d %x", 2 ** 64, 2 ** 64)`, "18446744073709551616 10000000000000000"},
+		{`json_stringify([2 ** 64])`, "[18446744073709551616]"},
+		{`let math = import "math"; math.abs(-(2 ** 64))`, "18446744073709551616"},
+		{`let math = import "math"; math.abs(-9223372036854775807 - 1)`, "9223372036854775808"},
+		{`let math = import "math"; math.pow(3, 50)`, "717897987691852588770249"},
+		{`let math = import "math"; math.max(2 ** 64, 2 ** 64 + 1, 1.5)`, "18446744073709551617"},
+		{`let math = import "math"; math.lcm(9223372036854775807, 2)`, "18446744073709551614"},
+		{`let math = import "math"; math.floor(math.pow(10, 30.0))`, "1000000000000000019884624838656"},
+		{`range(2 ** 64)`, "ERROR: argument to `range` out of range, got 18446744073709551616"},
+		{`(2 ** 64) / 0`, "ERROR: division by zero: 18446744073709551616 / 0"},
+		{`(2 ** 64) % 0`, "ERROR: modulo by zero: 18446744073709551616 % 0"},
+		{`0 ** -1`, "ERROR: division by zero: 0 ** -1"},
+		{`0 ** -(2 ** 64)`, "ERROR: division by zero: 0 ** -18446744073709551616"},
+		{`0 ** 0`, "1"},
+		{`1 << -(2 ** 64)`, "ERROR: negative shift count: 1 << -18446744073709551616"},
+		{`2 ** 2000000`, "ERROR: integer too large: 2 ** 2000000 has more than 1048576 bits"},
+		{`1 << 2000000`, "ERROR: integer too large: 1 << 2000000 has more than 1048576 bits"},
+		{`(1 << 1048575) * 4`, "ERROR: integer too large: more than 1048576 bits"},
+		{`1 ** 2000000000`, "1"},
+		{`now() + 2 ** 64`, "ERROR: duration out of range: 18446744073709551616"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(`let strings = import "strings"; ` + tt.input)
+		if got := inspectResult(evaluated); got != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestBigIntegersDemote(t *testing.T) {
+	for _, input := range []string{
+		`(2 ** 64) - (2 ** 64) + 1`,
+		`(1 << 64) >> 64`,
+		`99999999999999999999 / 99999999999999999999`,
+		`int("1")`,
+	} {
+		if _, ok := testEval(input).(*object.Integer); !ok {
+			t.Errorf("%s: result is not a small Integer. got=%T", input, testEval(input))
+		}
+	}
+}
+
+func inspectResult(obj object.Object) string {
+	if s, ok := obj.(*object.String); ok {
+		return s.Value
+	}
+	return obj.Inspect()
+}
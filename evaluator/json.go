@@ -0,0 +1,241 @@
+package evaluator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"interpreter/object"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// jsonParse decodes a JSON document: objects become hashes keeping the
+// order of their keys, numbers without a fraction or exponent become
+// integers and other numbers floats.
+func jsonParse(env *object.Environment, args ...object.Object) object.Object {
+	src, err := stringArg("json_parse", args[0])
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(strings.NewReader(src))
+	dec.UseNumber()
+	value, decodeErr := decodeJSON(dec)
+	if decodeErr != nil {
+		return jsonParseError(src, decodeErr)
+	}
+	end := int(dec.InputOffset())
+	if _, decodeErr := dec.Token(); decodeErr != io.EOF {
+		end += len(src[end:]) - len(strings.TrimLeft(src[end:], " \t\r\n"))
+		return newError("json_parse: unexpected data after the value%s", jsonPosition(src, end))
+	}
+	return value
+}
+
+func jsonParseError(src string, err error) *object.Error {
+	var syntaxErr *json.SyntaxError
+	switch {
+	case err == io.EOF || err == io.ErrUnexpectedEOF,
+		errors.As(err, &syntaxErr) && syntaxErr.Offset >= int64(len(src)):
+		return newError("json_parse: unexpected end of input")
+	case syntaxErr != nil && syntaxErr.Offset > 0:
+		return newError("json_parse: %s%s", err, jsonPosition(src, int(syntaxErr.Offset)-1))
+	}
+	return newError("json_parse: %s", err)
+}
+
+// jsonPosition describes the line and column of the byte at offset.
+func jsonPosition(src string, offset int) string {
+	before := src[:offset]
+	line := strings.Count(before, "\n") + 1
+	column := len(before) - strings.LastIndex(before, "\n")
+	return fmt.Sprintf(" at line %d, column %d", line, column)
+}
+
+func decodeJSON(dec *json.Decoder) (object.Object, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok := tok.(type) {
+	case json.Delim:
+		if tok == '[' {
+			array := &object.Array{Elements: []object.Object{}}
+			for dec.More() {
+				el, err := decodeJSON(dec)
+				if err != nil {
+					return nil, err
+				}
+				array.Elements = append(array.Elements, el)
+			}
+			_, err := dec.Token()
+			return array, err
+		}
+		hash := object.NewHash()
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			hash.Set(&object.String{Value: key.(string)}, value)
+		}
+		_, err := dec.Token()
+		return hash, err
+	case json.Number:
+		return decodeNumber(string(tok))
+	case string:
+		return &object.String{Value: tok}, nil
+	case bool:
+		return nativeBooleanVariable(tok), nil
+	default:
+		return NULL, nil
+	}
+}
+
+// decodeNumber decodes integers exactly, whatever their size, rather
+// than as rounded floats.
+func decodeNumber(s string) (object.Object, error) {
+	if !strings.ContainsAny(s, ".eE") {
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return &object.Integer{Value: i}, nil
+		}
+		i, _ := new(big.Int).SetString(s, 10)
+		if i.BitLen() > MAX_INTEGER_BITS {
+			return nil, fmt.Errorf("integer %s out of range", s)
+		}
+		return &object.BigInteger{Value: i}, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, fmt.Errorf("number %s out of range", s)
+	}
+	return &object.Float{Value: f}, nil
+}
+
+// jsonStringify encodes a value as JSON, on one line, or indented by the
+// optional second argument: a number of spaces or a string.
+func jsonStringify(env *object.Environment, args ...object.Object) object.Object {
+	e := &jsonEncoder{}
+	if len(args) == 2 {
+		switch indent := args[1].(type) {
+		case *object.Integer:
+			if indent.Value < 0 || indent.Value > 10 {
+				return newError("json_stringify: indent out of range, got %d", indent.Value)
+			}
+			e.indent = strings.Repeat(" ", int(indent.Value))
+		case *object.BigInteger:
+			return newError("json_stringify: indent out of range, got %s", indent.Inspect())
+		case *object.String:
+			e.indent = indent.Value
+		default:
+			return newError("argument to `json_stringify` not supported, got %s", args[1].Type())
+		}
+	}
+	if err := e.encode(args[0], "", ""); err != nil {
+		return err
+	}
+	return &object.String{Value: e.b.String()}
+}
+
+type jsonEncoder struct {
+	b      strings.Builder
+	indent string //"" for no newlines
+}
+
+// encode writes obj, found at path in the encoded value, at the given
+// indentation.
+func (e *jsonEncoder) encode(obj object.Object, path, indent string) *object.Error {
+	switch obj := obj.(type) {
+	case *object.Null:
+		e.b.WriteString("null")
+	case *object.Boolean, *object.Integer, *object.BigInteger:
+		e.b.WriteString(obj.Inspect())
+	case *object.Float:
+		if math.IsInf(obj.Value, 0) || math.IsNaN(obj.Value) {
+			return jsonUnsupported(obj.Inspect(), path)
+		}
+		e.b.WriteString(obj.Inspect())
+	case *object.String:
+		e.b.WriteString(jsonString(obj.Value))
+	case *object.Array:
+		if len(obj.Elements) == 0 {
+			e.b.WriteString("[]")
+			return nil
+		}
+		e.b.WriteString("[")
+		for i, el := range obj.Elements {
+			e.separate(i, indent+e.indent)
+			if err := e.encode(el, fmt.Sprintf("%s[%d]", path, i), indent+e.indent); err != nil {
+				return err
+			}
+		}
+		e.newline(indent)
+		e.b.WriteString("]")
+	case *object.Hash:
+		if len(obj.Keys) == 0 {
+			e.b.WriteString("{}")
+			return nil
+		}
+		e.b.WriteString("{")
+		for i, pair := range obj.Ordered() {
+			key, ok := pair.Key.(*object.String)
+			if !ok {
+				return newError("json_stringify: object keys must be STRING, got %s%s",
+					pair.Key.Type(), jsonPath(path))
+			}
+			e.separate(i, indent+e.indent)
+			e.b.WriteString(jsonString(key.Value) + ":")
+			if e.indent != "" {
+				e.b.WriteString(" ")
+			}
+			if err := e.encode(pair.Value, path+"["+jsonString(key.Value)+"]", indent+e.indent); err != nil {
+				return err
+			}
+		}
+		e.newline(indent)
+		e.b.WriteString("}")
+	default:
+		return jsonUnsupported(string(obj.Type()), path)
+	}
+	return nil
+}
+
+// separate starts the i-th element of an array or object.
+func (e *jsonEncoder) separate(i int, indent string) {
+	if i > 0 {
+		e.b.WriteString(",")
+	}
+	e.newline(indent)
+}
+
+func (e *jsonEncoder) newline(indent string) {
+	if e.indent != "" {
+		e.b.WriteString("\n" + indent)
+	}
+}
+
+func jsonUnsupported(what, path string) *object.Error {
+	return newError("json_stringify: %s cannot be encoded%s", what, jsonPath(path))
+}
+
+// jsonPath describes where in the encoded value an error is.
+func jsonPath(path string) string {
+	if path == "" {
+		return ""
+	}
+	return " at " + path
+}
+
+func jsonString(s string) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
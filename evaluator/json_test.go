@@ -0,0 +1,80 @@
+package evaluator
+
+import (
+	"interpreter/lexer"
+	"interpreter/object"
+	"interpreter/parser"
+	"testing"
+)
+
+// evalJSON evaluates input with src bound to a string, which literals
+// cannot hold when it contains quotes.
+func evalJSON(input, src string) object.Object {
+	env := object.NewEnvironment()
+	env.Set("src", &object.String{Value: src})
+	return Eval(parser.New(lexer.New(input)).ParseProgram(), env)
+}
+
+func TestJSONParse(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{`{"b": [1, -2.5, "x", true, null], "a": {}}`, `{"b": [1, -2.5, "x", true, null], "a": {}}`},
+		{` [1e3, 0.5, "é\n"] `, "[1000.0, 0.5, \"é\n\"]"},
+		{`{"a": 1, "a": 2}`, `{"a": 2}`},
+		{`9223372036854775807`, "9223372036854775807"},
+		{`-9223372036854775808`, "-9223372036854775808"},
+		{`9223372036854775808`, "9223372036854775808"},
+		{`[-123456789012345678901234567890]`, "[-123456789012345678901234567890]"},
+		{`1e999`, "ERROR: json_parse: number 1e999 out of range"},
+		{`[1, 2`, "ERROR: json_parse: unexpected end of input"},
+		{``, "ERROR: json_parse: unexpected end of input"},
+		{"[1,\n 2 3]", "ERROR: json_parse: invalid character '3' after array element at line 2, column 4"},
+		{`{1: 2}`, "ERROR: json_parse: object member name must be a string at line 1, column 2"},
+		{`[1] ]`, "ERROR: json_parse: unexpected data after the value at line 1, column 5"},
+	}
+	for _, tt := range tests {
+		evaluated := evalJSON("json_parse(src)", tt.src)
+		if got := evaluated.Inspect(); got != tt.expected {
+			t.Errorf("wrong result for %q. expected=%q, got=%q", tt.src, tt.expected, got)
+		}
+	}
+
+	hash := evalJSON(`json_parse(src)["a"][1]`, `{"a": [1, {"b": 2}]}`)
+	if hash.Inspect() != `{"b": 2}` {
+		t.Errorf("wrong nested value. got=%s", hash.Inspect())
+	}
+}
+
+func TestJSONStringify(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`json_stringify({"b": [1, 2.0, "x<y"], "a": {}, "c": true, "d": []})`,
+			`{"b":[1,2.0,"x<y"],"a":{},"c":true,"d":[]}`},
+		{`json_stringify(if (false) { 1 })`, "null"},
+		{`json_stringify({"a": [1, {"b": false}]}, 2)`,
+			"{\n  \"a\": [\n    1,\n    {\n      \"b\": false\n    }\n  ]\n}"},
+		{`json_stringify([1], "	")`, "[\n\t1\n]"},
+		{`json_stringify(fn(x) { x })`, "ERROR: json_stringify: FUNCTION cannot be encoded"},
+		{`json_stringify([1, {"f": len}])`, `ERROR: json_stringify: BUILTIN cannot be encoded at [1]["f"]`},
+		{`json_stringify({"a": {1: 2}})`, `ERROR: json_stringify: object keys must be STRING, got INTEGER at ["a"]`},
+		{`json_stringify(1.0 / 0.0)`, "ERROR: json_stringify: +Inf cannot be encoded"},
+		{`json_stringify(1, -1)`, "ERROR: json_stringify: indent out of range, got -1"},
+		{`json_stringify(1, true)`, "ERROR: argument to `json_stringify` not supported, got BOOLEAN"},
+	}
+	for _, tt := range tests {
+		if got := testEval(tt.input).Inspect(); got != tt.expected {
+			t.Errorf("wrong result for %q. expected=%q, got=%q", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	src := `{"id":9223372036854775807,"min":-9223372036854775808,"big":18446744073709551616,"f":0.1,"s":"a\"b\\c","l":[[],{}]}`
+	if got := evalJSON("json_stringify(json_parse(src))", src).Inspect(); got != src {
+		t.Errorf("round trip changed the value. expected=%q, got=%q", src, got)
+	}
+}
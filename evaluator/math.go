@@ -0,0 +1,246 @@
+package evaluator
+
+import (
+	"interpreter/object"
+	"math"
+	"math/big"
+)
+
+// mathModule is imported with `import "math"`.
+var mathModule = &object.Module{Path: "math", Exports: map[string]object.Object{
+	"pi": &object.Float{Value: math.Pi},
+	"e":  &object.Float{Value: math.E},
+
+	"abs": &object.Builtin{MinArgs: 1, MaxArgs: 1, Fn: mathAbs},
+	"min": &object.Builtin{MinArgs: 1, MaxArgs: -1, Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		return mathExtreme("min", args, numberLess)
+	}},
+	"max": &object.Builtin{MinArgs: 1, MaxArgs: -1, Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		return mathExtreme("max", args, func(a, b object.Object) bool { return numberLess(b, a) })
+	}},
+	"pow":   &object.Builtin{MinArgs: 2, MaxArgs: 2, Fn: mathPow},
+	"floor": mathRound("floor", math.Floor),
+	"ceil":  mathRound("ceil", math.Ceil),
+	"log":   &object.Builtin{MinArgs: 1, MaxArgs: 2, Fn: mathLog},
+
+	"sqrt":  mathFloat("sqrt", math.Sqrt),
+	"exp":   mathFloat("exp", math.Exp),
+	"sin":   mathFloat("sin", math.Sin),
+	"cos":   mathFloat("cos", math.Cos),
+	"tan":   mathFloat("tan", math.Tan),
+	"asin":  mathFloat("asin", math.Asin),
+	"acos":  mathFloat("acos", math.Acos),
+	"atan":  mathFloat("atan", math.Atan),
+	"atan2": &object.Builtin{MinArgs: 2, MaxArgs: 2, Fn: mathAtan2},
+
+	"gcd": &object.Builtin{MinArgs: 2, MaxArgs: 2, Fn: mathGcd},
+	"lcm": &object.Builtin{MinArgs: 2, MaxArgs: 2, Fn: mathLcm},
+	"mod": &object.Builtin{MinArgs: 2, MaxArgs: 2, Fn: mathMod},
+}}
+
+// numberArg returns the value of an integer or float argument of the
+// builtin name as a float.
+func numberArg(name string, arg object.Object) (float64, *object.Error) {
+	if !isNumber(arg) {
+		return 0, newError("argument to `%s` not supported, got %s", name, arg.Type())
+	}
+	return toFloat(arg), nil
+}
+
+// intArg returns the value of an integer argument of the builtin name,
+// which must fit an int64.
+func intArg(name string, arg object.Object) (int64, *object.Error) {
+	switch i := arg.(type) {
+	case *object.Integer:
+		return i.Value, nil
+	case *object.BigInteger:
+		return 0, newError("argument to `%s` out of range, got %s", name, arg.Inspect())
+	default:
+		return 0, newError("argument to `%s` not supported, got %s", name, arg.Type())
+	}
+}
+
+// floatResult turns a result that is not a number back into an error
+// on the arguments it was computed from.
+func floatResult(name string, value float64, args ...object.Object) object.Object {
+	if math.IsNaN(value) {
+		for _, arg := range args {
+			if math.IsNaN(toFloat(arg)) {
+				return &object.Float{Value: value}
+			}
+		}
+		return newError("argument to `%s` out of domain, got %s", name, args[0].Inspect())
+	}
+	return &object.Float{Value: value}
+}
+
+// mathFloat makes a builtin applying fn to one number.
+func mathFloat(name string, fn func(float64) float64) *object.Builtin {
+	return &object.Builtin{MinArgs: 1, MaxArgs: 1, Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		x, err := numberArg(name, args[0])
+		if err != nil {
+			return err
+		}
+		return floatResult(name, fn(x), args[0])
+	}}
+}
+
+// mathRound makes a builtin rounding a number to an integer with fn.
+func mathRound(name string, fn func(float64) float64) *object.Builtin {
+	return &object.Builtin{MinArgs: 1, MaxArgs: 1, Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		if args[0].Type() == object.INTEGER_OBJ {
+			return args[0]
+		}
+		x, err := numberArg(name, args[0])
+		if err != nil {
+			return err
+		}
+		r, ok := integerFromFloat(fn(x))
+		if !ok {
+			return newError("result of `%s` out of integer range, got %s", name, args[0].Inspect())
+		}
+		return r
+	}}
+}
+
+func mathAbs(env *object.Environment, args ...object.Object) object.Object {
+	switch arg := args[0].(type) {
+	case *object.Integer, *object.BigInteger:
+		if compareIntegers(arg, &object.Integer{Value: 0}) < 0 {
+			return evalMinusOperatorExpression(arg)
+		}
+		return arg
+	case *object.Float:
+		return &object.Float{Value: math.Abs(arg.Value)}
+	default:
+		return newError("argument to `abs` not supported, got %s", arg.Type())
+	}
+}
+
+// mathExtreme returns the argument that is before all others according
+// to less, keeping its type.
+func mathExtreme(name string, args []object.Object, less func(a, b object.Object) bool) object.Object {
+	best := args[0]
+	for _, arg := range args {
+		if !isNumber(arg) {
+			return newError("argument to `%s` not supported, got %s", name, arg.Type())
+		}
+		if less(arg, best) {
+			best = arg
+		}
+	}
+	return best
+}
+
+// mathPow raises integers to non-negative integer powers exactly, and
+// everything else in floating point.
+func mathPow(env *object.Environment, args ...object.Object) object.Object {
+	if args[0].Type() == object.INTEGER_OBJ && args[1].Type() == object.INTEGER_OBJ &&
+		compareIntegers(args[1], &object.Integer{Value: 0}) >= 0 {
+		return evalIntegerInfixExpression(args[0], "**", args[1])
+	}
+	x, err := numberArg("pow", args[0])
+	if err != nil {
+		return err
+	}
+	y, err := numberArg("pow", args[1])
+	if err != nil {
+		return err
+	}
+	return floatResult("pow", math.Pow(x, y), args...)
+}
+
+// mathLog returns the natural logarithm, or the logarithm in the base
+// given as second argument.
+func mathLog(env *object.Environment, args ...object.Object) object.Object {
+	x, err := numberArg("log", args[0])
+	if err != nil {
+		return err
+	}
+	if x <= 0 {
+		return newError("argument to `log` out of domain, got %s", args[0].Inspect())
+	}
+	if len(args) == 1 {
+		return &object.Float{Value: math.Log(x)}
+	}
+	base, err := numberArg("log", args[1])
+	if err != nil {
+		return err
+	}
+	switch base {
+	case 2:
+		return &object.Float{Value: math.Log2(x)}
+	case 10:
+		return &object.Float{Value: math.Log10(x)}
+	}
+	if base <= 0 || base == 1 {
+		return newError("base of `log` out of domain, got %s", args[1].Inspect())
+	}
+	return &object.Float{Value: math.Log(x) / math.Log(base)}
+}
+
+func mathAtan2(env *object.Environment, args ...object.Object) object.Object {
+	y, err := numberArg("atan2", args[0])
+	if err != nil {
+		return err
+	}
+	x, err := numberArg("atan2", args[1])
+	if err != nil {
+		return err
+	}
+	return &object.Float{Value: math.Atan2(y, x)}
+}
+
+func intArgs(name string, args []object.Object) (int64, int64, *object.Error) {
+	a, err := intArg(name, args[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	b, err := intArg(name, args[1])
+	if err != nil {
+		return 0, 0, err
+	}
+	return a, b, nil
+}
+
+func gcd(a, b int64) *big.Int {
+	return new(big.Int).GCD(nil, nil, big.NewInt(a), big.NewInt(b))
+}
+
+func mathGcd(env *object.Environment, args ...object.Object) object.Object {
+	a, b, err := intArgs("gcd", args)
+	if err != nil {
+		return err
+	}
+	return integerObject(gcd(a, b))
+}
+
+func mathLcm(env *object.Environment, args ...object.Object) object.Object {
+	a, b, err := intArgs("lcm", args)
+	if err != nil {
+		return err
+	}
+	if a == 0 || b == 0 {
+		return &object.Integer{Value: 0}
+	}
+	lcm := new(big.Int).Quo(big.NewInt(a), gcd(a, b))
+	lcm.Mul(lcm, big.NewInt(b))
+	return integerObject(lcm.Abs(lcm))
+}
+
+// mathMod returns the remainder of the floored division of a by b, which
+// has the sign of b.
+func mathMod(env *object.Environment, args ...object.Object) object.Object {
+	a, b, err := intArgs("mod", args)
+	if err != nil {
+		return err
+	}
+	if b == 0 {
+		return newError("division by zero: mod(%d, %d)", a, b)
+	}
+	m := a % b
+	if m != 0 && (m < 0) != (b < 0) {
+		m += b
+	}
+	return &object.Integer{Value: m}
+}
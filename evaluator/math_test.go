@@ -0,0 +1,119 @@
+package evaluator
+
+import (
+	"interpreter/object"
+	"math"
+	"testing"
+)
+
+func TestMathModule(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`math.pi`, math.Pi},
+		{`math.e`, math.E},
+		{`math.abs(-3)`, 3},
+		{`math.abs(3)`, 3},
+		{`math.abs(-2.5)`, 2.5},
+		{`math.abs("a")`, "argument to `abs` not supported, got STRING"},
+		{`math.min(3, 1, 2)`, 1},
+		{`math.min(3, 1.5)`, 1.5},
+		{`math.max(3, 1, 2)`, 3},
+		{`math.max(7)`, 7},
+		{`math.max(1, true)`, "argument to `max` not supported, got BOOLEAN"},
+		{`math.max()`, "wrong number of arguments. got=0, want at least 1"},
+		{`math.pow(2, 10)`, 1024},
+		{`math.pow(-3, 3)`, -27},
+		{`math.pow(2, -1)`, 0.5},
+		{`math.pow(4, 0.5)`, 2.0},
+		{`math.pow(-8, 0.5)`, "argument to `pow` out of domain, got -8"},
+		{`math.pow(2)`, "wrong number of arguments. got=1, want=2"},
+		{`math.sqrt(16)`, 4.0},
+		{`math.sqrt(2.25)`, 1.5},
+		{`math.sqrt(-1)`, "argument to `sqrt` out of domain, got -1"},
+		{`math.sqrt("4")`, "argument to `sqrt` not supported, got STRING"},
+		{`math.floor(2.7)`, 2},
+		{`math.floor(-2.5)`, -3},
+		{`math.floor(5)`, 5},
+		{`math.ceil(2.1)`, 3},
+		{`math.ceil(-2.5)`, -2},
+		{`math.ceil(math.pow(10, 400.0))`, "result of `ceil` out of integer range, got +Inf"},
+		{`math.log(math.e)`, 1.0},
+		{`math.log(8, 2)`, 3.0},
+		{`math.log(1000, 10)`, 3.0},
+		{`math.log(81, 3)`, 4.0},
+		{`math.log(0)`, "argument to `log` out of domain, got 0"},
+		{`math.log(8, 1)`, "base of `log` out of domain, got 1"},
+		{`math.exp(0)`, 1.0},
+		{`math.sin(0)`, 0.0},
+		{`math.cos(math.pi)`, -1.0},
+		{`math.tan(math.pi / 4)`, 1.0},
+		{`math.asin(1)`, math.Pi / 2},
+		{`math.acos(1)`, 0.0},
+		{`math.acos(2)`, "argument to `acos` out of domain, got 2"},
+		{`math.atan(1)`, math.Pi / 4},
+		{`math.atan2(1, -1)`, 3 * math.Pi / 4},
+		{`math.gcd(12, 18)`, 6},
+		{`math.gcd(-12, 18)`, 6},
+		{`math.gcd(0, 0)`, 0},
+		{`math.gcd(-9223372036854775808, 0) == 2 ** 63`, true},
+		{`math.gcd(-9223372036854775808, -9223372036854775808) == 2 ** 63`, true},
+		{`math.gcd(-9223372036854775808, 6)`, 2},
+		{`math.gcd(1.5, 3)`, "argument to `gcd` not supported, got FLOAT"},
+		{`math.lcm(4, 6)`, 12},
+		{`math.lcm(-4, 6)`, 12},
+		{`math.lcm(0, 6)`, 0},
+		{`math.lcm(-9223372036854775808, 3) == 3 * 2 ** 63`, true},
+		{`math.mod(7, 3)`, 1},
+		{`math.mod(-7, 3)`, 2},
+		{`math.mod(7, -3)`, -2},
+		{`math.mod(-6, 3)`, 0},
+		{`math.mod(7, 0)`, "division by zero: mod(7, 0)"},
+		{`math.nope`, "module math has no exported member nope"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(`let math = import "math"; ` + tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case float64:
+			result, ok := evaluated.(*object.Float)
+			if !ok {
+				t.Errorf("%s: object is not Float. got=%T (%+v)", tt.input, evaluated, evaluated)
+				continue
+			}
+			if math.Abs(result.Value-expected) > 1e-9 {
+				t.Errorf("%s: object has wrong value. got=%g, want=%g", tt.input, result.Value, expected)
+			}
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("%s: object is not Error. got=%T (%+v)", tt.input, evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q",
+					expected, errObj.Message)
+			}
+		}
+	}
+}
+
+func TestFloatInspect(t *testing.T) {
+	tests := []struct {
+		value    float64
+		expected string
+	}{
+		{2, "2.0"},
+		{-0.5, "-0.5"},
+		{1e21, "1e+21"},
+		{math.Inf(1), "+Inf"},
+		{math.NaN(), "NaN"},
+	}
+	for _, tt := range tests {
+		if got := (&object.Float{Value: tt.value}).Inspect(); got != tt.expected {
+			t.Errorf("wrong Inspect. expected=%q, got=%q", tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package evaluator
+
+import (
+	"interpreter/ast"
+	"interpreter/lexer"
+	"interpreter/object"
+	"interpreter/parser"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ModuleExt is appended to import paths that have no extension.
+const ModuleExt = ".tl"
+
+func evalExportStatement(es *ast.ExportStatement, env *object.Environment) object.Object {
+	val := Eval(es.Statement, env)
+	if isError(val) {
+		return val
+	}
+	env.Export(es.Statement.Name.Value)
+	return nil
+}
+
+// stdlib holds the modules built into the interpreter, by name. Importing
+// them never looks for a file.
+var stdlib = map[string]*object.Module{
+	"math":    mathModule,
+	"strings": stringsModule,
+}
+
+func evalImportExpression(ie *ast.ImportExpression, env *object.Environment) object.Object {
+	if module, ok := stdlib[ie.Path.Value]; ok {
+		return module
+	}
+	path, err := resolveModulePath(ie.Path.Value, env)
+	if err != nil {
+		return err
+	}
+	rt := env.Runtime()
+	if err := checkModulePath(ie.Path.Value, path, rt); err != nil {
+		return err
+	}
+	if module, ok := rt.Modules[path]; ok {
+		return module
+	}
+	for i, loading := range rt.Loading {
+		if loading == path {
+			cycle := append(append([]string{}, rt.Loading[i:]...), path)
+			return newError("import cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+	return loadModule(path, rt)
+}
+
+// LoadFile evaluates the source file at path as the main module of rt and
+// returns it, or the first error it raised. Imports inside the file are
+// resolved relative to it.
+func LoadFile(path string, rt *object.Runtime) object.Object {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return newError("%s", err)
+	}
+	return loadModule(abs, rt)
+}
+
+func loadModule(path string, rt *object.Runtime) object.Object {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return newError("could not read module: %s", err)
+	}
+	p := parser.New(lexer.New(string(src)))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return newError("could not parse module %s: %s", path, strings.Join(p.Errors(), "; "))
+	}
+
+	rt.Loading = append(rt.Loading, path)
+	defer func() { rt.Loading = rt.Loading[:len(rt.Loading)-1] }()
+
+	env := object.NewModuleEnvironment(rt, path)
+	if result := Eval(program, env); isError(result) {
+		return result
+	}
+	module := &object.Module{Path: path, Exports: env.Exports()}
+	rt.Modules[path] = module
+	return module
+}
+
+// checkModulePath confines imports like the file builtins: with FileRoot
+// set, only modules below it or in a search path directory may be loaded.
+func checkModulePath(name, path string, rt *object.Runtime) *object.Error {
+	if rt.FilesDisabled {
+		return newError("file access is disabled: import")
+	}
+	if rt.FileRoot == "" {
+		return nil
+	}
+	for _, dir := range append([]string{rt.FileRoot}, rt.SearchPath...) {
+		dir, err := filepath.Abs(dir)
+		if err == nil && within(dir, path) && within(evalSymlinks(dir), evalSymlinks(path)) {
+			return nil
+		}
+	}
+	return newError("import: module %s is outside the root directory", name)
+}
+
+// resolveModulePath finds the file an import refers to. Paths starting with
+// "./" or "../" are relative to the importing file only; other paths are
+// also looked up in the runtime search path.
+func resolveModulePath(name string, env *object.Environment) (string, *object.Error) {
+	if filepath.Ext(name) == "" {
+		name += ModuleExt
+	}
+	if filepath.IsAbs(name) {
+		return name, nil
+	}
+
+	dir := "."
+	if env.File() != "" {
+		dir = filepath.Dir(env.File())
+	}
+	dirs := []string{dir}
+	if !strings.HasPrefix(name, "./") && !strings.HasPrefix(name, "../") {
+		dirs = append(dirs, env.Runtime().SearchPath...)
+	}
+
+	for _, d := range dirs {
+		candidate, err := filepath.Abs(filepath.Join(d, name))
+		if err != nil {
+			continue
+		}
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, nil
+		}
+	}
+	return "", newError("module not found: %s", name)
+}
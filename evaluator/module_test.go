@@ -0,0 +1,170 @@
+package evaluator
+
+import (
+	"interpreter/lexer"
+	"interpreter/object"
+	"interpreter/parser"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeModules(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func testEvalFile(t *testing.T, dir string, input string, searchPath ...string) object.Object {
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser errors: %v", p.Errors())
+	}
+	rt := object.NewRuntime()
+	rt.SearchPath = searchPath
+	env := object.NewModuleEnvironment(rt, filepath.Join(dir, "main.tl"))
+	return Eval(program, env)
+}
+
+func TestImportExpressions(t *testing.T) {
+	dir := writeModules(t, map[string]string{
+		"lib/math.tl": `
+		let square = fn(x) { x * x };
+		export let cube = fn(x) { square(x) * x };
+		export let answer = 42;`,
+		"lib/app.tl": `
+		let m = import "./math";
+		export let twice = fn(x) { m.answer * x };`,
+	})
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`let m = import "lib/math"; m.answer;`, 42},
+		{`let m = import "./lib/math.tl"; m["answer"];`, 42},
+		{`let m = import "lib/math"; m.cube(3);`, 27},
+		{`import "lib/math".answer;`, 42},
+		{`let app = import "lib/app"; app.twice(2);`, 84},
+		{`let m = import "lib/math"; m.square(3);`, "module " + filepath.Join(dir, "lib/math.tl") + " has no exported member square"},
+		{`let m = import "lib/math"; m[1];`, "index operator not supported: MODULE"},
+		{`let m = import "lib/missing";`, "module not found: lib/missing.tl"},
+		{`5.answer`, "member access not supported: INTEGER"},
+	}
+	for _, tt := range tests {
+		evaluated := testEvalFile(t, dir, tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q",
+					expected, errObj.Message)
+			}
+		}
+	}
+}
+
+func TestImportCache(t *testing.T) {
+	dir := writeModules(t, map[string]string{
+		"lib.tl": `export let f = fn() { 1 };`,
+	})
+	input := `
+	let a = import "lib";
+	let b = import "./lib.tl";
+	a.f == b.f;`
+	testBooleanObject(t, testEvalFile(t, dir, input), true)
+}
+
+func TestImportSearchPath(t *testing.T) {
+	libDir := writeModules(t, map[string]string{
+		"shared/strings.tl": `export let greeting = "hello";`,
+	})
+	dir := writeModules(t, map[string]string{})
+
+	evaluated := testEvalFile(t, dir, `import "shared/strings".greeting`, libDir)
+	str, ok := evaluated.(*object.String)
+	if !ok {
+		t.Fatalf("object is not String. got=%T (%+v)", evaluated, evaluated)
+	}
+	if str.Value != "hello" {
+		t.Errorf("String has wrong value. got=%q", str.Value)
+	}
+
+	// explicitly relative imports never consult the search path
+	evaluated = testEvalFile(t, dir, `import "./shared/strings"`, libDir)
+	if _, ok := evaluated.(*object.Error); !ok {
+		t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+	}
+}
+
+func TestImportConfined(t *testing.T) {
+	libDir := writeModules(t, map[string]string{
+		"shared.tl": `export let x = 1;`,
+	})
+	outside := writeModules(t, map[string]string{
+		"secret.tl": `export let x = 2;`,
+	})
+	dir := writeModules(t, map[string]string{
+		"lib.tl": `export let x = 3;`,
+	})
+	if err := os.Symlink(filepath.Join(outside, "secret.tl"), filepath.Join(dir, "link.tl")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`import "lib".x`, 3},
+		{`import "shared".x`, 1},
+		{`import "math".pi > 3`, true},
+		{`import "` + filepath.Join(outside, "secret") + `"`,
+			"import: module " + filepath.Join(outside, "secret") + " is outside the root directory"},
+		{`import "./link"`, "import: module ./link is outside the root directory"},
+	}
+	for _, tt := range tests {
+		rt := object.NewRuntime()
+		rt.FileRoot = dir
+		rt.SearchPath = []string{libDir}
+		env := object.NewModuleEnvironment(rt, filepath.Join(dir, "main.tl"))
+		testValue(t, tt.input, Eval(parser.New(lexer.New(tt.input)).ParseProgram(), env), tt.expected)
+	}
+
+	rt := object.NewRuntime()
+	rt.FilesDisabled = true
+	env := object.NewModuleEnvironment(rt, filepath.Join(dir, "main.tl"))
+	input := `import "lib"`
+	testValue(t, input, Eval(parser.New(lexer.New(input)).ParseProgram(), env), "file access is disabled: import")
+}
+
+func TestImportCycle(t *testing.T) {
+	dir := writeModules(t, map[string]string{
+		"a.tl": `let b = import "b"; export let x = 1;`,
+		"b.tl": `let a = import "a"; export let y = 2;`,
+	})
+	evaluated := testEvalFile(t, dir, `import "a"`)
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+	}
+	a, b := filepath.Join(dir, "a.tl"), filepath.Join(dir, "b.tl")
+	expected := "import cycle: " + a + " -> " + b + " -> " + a
+	if errObj.Message != expected {
+		t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+	}
+}
@@ -0,0 +1,70 @@
+package evaluator
+
+import (
+	"interpreter/object"
+	"math"
+)
+
+// random returns a float in [0, 1).
+func random(env *object.Environment, args ...object.Object) object.Object {
+	return &object.Float{Value: env.Runtime().Rand.Float64()}
+}
+
+// randomInt returns an integer between lo and hi, both included.
+func randomInt(env *object.Environment, args ...object.Object) object.Object {
+	lo, hi, err := intArgs("random_int", args)
+	if err != nil {
+		return err
+	}
+	if hi < lo {
+		return newError("empty range in `random_int`: %d to %d", lo, hi)
+	}
+	r := env.Runtime().Rand
+	span := uint64(hi - lo)
+	if span < math.MaxInt64 {
+		return &object.Integer{Value: lo + r.Int63n(int64(span)+1)}
+	}
+	// more than half of all integers: draw until one is in range
+	n := r.Uint64()
+	for n > span {
+		n = r.Uint64()
+	}
+	return &object.Integer{Value: lo + int64(n)}
+}
+
+// choice returns an element of a non-empty array.
+func choice(env *object.Environment, args ...object.Object) object.Object {
+	array, ok := args[0].(*object.Array)
+	if !ok {
+		return newError("argument to `choice` not supported, got %s", args[0].Type())
+	}
+	if len(array.Elements) == 0 {
+		return newError("cannot choose from an empty array")
+	}
+	return array.Elements[env.Runtime().Rand.Intn(len(array.Elements))]
+}
+
+// shuffle returns the elements of an array in a random order.
+func shuffle(env *object.Environment, args ...object.Object) object.Object {
+	array, ok := args[0].(*object.Array)
+	if !ok {
+		return newError("argument to `shuffle` not supported, got %s", args[0].Type())
+	}
+	elements := make([]object.Object, len(array.Elements))
+	copy(elements, array.Elements)
+	env.Runtime().Rand.Shuffle(len(elements), func(i, j int) {
+		elements[i], elements[j] = elements[j], elements[i]
+	})
+	return &object.Array{Elements: elements}
+}
+
+// seed restarts the random numbers of the interpreter from n, so that
+// runs seeded alike draw the same numbers.
+func seed(env *object.Environment, args ...object.Object) object.Object {
+	n, err := intArg("seed", args[0])
+	if err != nil {
+		return err
+	}
+	env.Runtime().Rand.Seed(n)
+	return NULL
+}
@@ -0,0 +1,60 @@
+package evaluator
+
+import (
+	"interpreter/lexer"
+	"interpreter/object"
+	"interpreter/parser"
+	"testing"
+)
+
+func TestRandom(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`let x = random(); if (x < 0) { false } else { x < 1 }`, true},
+		{`random_int(3, 3)`, 3},
+		{`let n = random_int(-9223372036854775807 - 1, 9223372036854775807); n - n`, 0},
+		{`choice([7])`, 7},
+		{`len(shuffle([1, 2, 3]))`, 3},
+		{`shuffle([])`, []int{}},
+		{`random_int(2, 1)`, "empty range in `random_int`: 2 to 1"},
+		{`random_int(1, 2.0)`, "argument to `random_int` not supported, got FLOAT"},
+		{`choice([])`, "cannot choose from an empty array"},
+		{`choice("ab")`, "argument to `choice` not supported, got STRING"},
+		{`shuffle(1)`, "argument to `shuffle` not supported, got INTEGER"},
+		{`seed("1")`, "argument to `seed` not supported, got STRING"},
+	}
+	for _, tt := range tests {
+		testValue(t, tt.input, testEval(tt.input), tt.expected)
+	}
+
+	env := object.NewEnvironment()
+	Eval(parser.New(lexer.New(`seed(1)`)).ParseProgram(), env)
+	for i := 0; i < 100; i++ {
+		n := Eval(parser.New(lexer.New(`random_int(1, 6)`)).ParseProgram(), env).(*object.Integer).Value
+		if n < 1 || n > 6 {
+			t.Fatalf("random_int out of range. got=%d", n)
+		}
+	}
+}
+
+func TestSeed(t *testing.T) {
+	input := `seed(42); [random(), random_int(0, 1000), choice([1, 2, 3]), shuffle([1, 2, 3, 4, 5])]`
+	first := testEval(input).Inspect()
+
+	// another interpreter drawing numbers in between does not change the
+	// numbers of the first
+	env := object.NewEnvironment()
+	other := object.NewEnvironment()
+	Eval(parser.New(lexer.New(`seed(42)`)).ParseProgram(), env)
+	Eval(parser.New(lexer.New(`seed(42); random(); random()`)).ParseProgram(), other)
+	second := Eval(parser.New(lexer.New(input[len("seed(42); "):])).ParseProgram(), env).Inspect()
+
+	if first != second {
+		t.Errorf("seeded runs differ. first=%s, second=%s", first, second)
+	}
+	if third := testEval(`seed(43); random()`).Inspect(); first[1:len(third)+1] == third {
+		t.Errorf("different seeds drew the same number %s", third)
+	}
+}
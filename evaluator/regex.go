@@ -0,0 +1,158 @@
+package evaluator
+
+import (
+	"interpreter/object"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+)
+
+// REGEX_FLAGS are the flags a regex can have: case-insensitive,
+// multi-line, dot matches newline, and ungreedy.
+const REGEX_FLAGS = "imsU"
+
+// compileRegex compiles a regex literal or the arguments of `regex`.
+func compileRegex(pattern, flags string) object.Object {
+	for _, flag := range flags {
+		if !strings.ContainsRune(REGEX_FLAGS, flag) {
+			return newError("invalid regex flag %q, want one of %s", flag, REGEX_FLAGS)
+		}
+	}
+	expr := pattern
+	if flags != "" {
+		expr = "(?" + flags + ")" + pattern
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		message := err.Error()
+		if syntaxErr, ok := err.(*syntax.Error); ok {
+			message = string(syntaxErr.Code) + ": `" + syntaxErr.Expr + "`"
+		}
+		return newError("invalid regex /%s/: %s", pattern, message)
+	}
+	return &object.Regex{Value: re, Pattern: pattern, Flags: flags}
+}
+
+func regexBuiltin(env *object.Environment, args ...object.Object) object.Object {
+	values, err := stringArgs("regex", args)
+	if err != nil {
+		return err
+	}
+	flags := ""
+	if len(values) == 2 {
+		flags = values[1]
+	}
+	return compileRegex(values[0], flags)
+}
+
+// regexMethods are called as re.name(args); Fn is given the regex before
+// the arguments, which MinArgs and MaxArgs do not count.
+var regexMethods = map[string]*object.Builtin{
+	"match":    &object.Builtin{MinArgs: 1, MaxArgs: 1, Fn: regexMatch},
+	"find":     &object.Builtin{MinArgs: 1, MaxArgs: 1, Fn: regexFind},
+	"find_all": &object.Builtin{MinArgs: 1, MaxArgs: 2, Fn: regexFindAll},
+	"replace":  &object.Builtin{MinArgs: 2, MaxArgs: 2, Fn: regexReplace},
+	"split":    &object.Builtin{MinArgs: 1, MaxArgs: 2, Fn: regexSplit},
+}
+
+// regexMethod binds the method name to re.
+func regexMethod(re *object.Regex, name string) object.Object {
+	method, ok := regexMethods[name]
+	if !ok {
+		return newError("regex has no method %s", name)
+	}
+	return &object.Builtin{MinArgs: method.MinArgs, MaxArgs: method.MaxArgs,
+		Fn: func(env *object.Environment, args ...object.Object) object.Object {
+			return method.Fn(env, append([]object.Object{re}, args...)...)
+		}}
+}
+
+// limitArg returns the optional limit on the number of matches, all of
+// them when omitted.
+func limitArg(name string, args []object.Object, i int) (int, *object.Error) {
+	if len(args) <= i {
+		return -1, nil
+	}
+	n, err := intArg(name, args[i])
+	if err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+// regexMatch reports whether the regex matches anywhere in the string.
+func regexMatch(env *object.Environment, args ...object.Object) object.Object {
+	s, err := stringArg("match", args[1])
+	if err != nil {
+		return err
+	}
+	return nativeBooleanVariable(args[0].(*object.Regex).Value.MatchString(s))
+}
+
+// regexFind returns the first match followed by its groups, null for the
+// groups that did not take part in it, or null when nothing matches.
+func regexFind(env *object.Environment, args ...object.Object) object.Object {
+	s, err := stringArg("find", args[1])
+	if err != nil {
+		return err
+	}
+	match := args[0].(*object.Regex).Value.FindStringSubmatchIndex(s)
+	if match == nil {
+		return NULL
+	}
+	return submatches(s, match)
+}
+
+// regexFindAll returns every match, or at most n, like find.
+func regexFindAll(env *object.Environment, args ...object.Object) object.Object {
+	s, err := stringArg("find_all", args[1])
+	if err != nil {
+		return err
+	}
+	n, err := limitArg("find_all", args, 2)
+	if err != nil {
+		return err
+	}
+	matches := args[0].(*object.Regex).Value.FindAllStringSubmatchIndex(s, n)
+	elements := make([]object.Object, len(matches))
+	for i, match := range matches {
+		elements[i] = submatches(s, match)
+	}
+	return &object.Array{Elements: elements}
+}
+
+func submatches(s string, match []int) *object.Array {
+	elements := make([]object.Object, len(match)/2)
+	for i := range elements {
+		if start := match[2*i]; start < 0 {
+			elements[i] = NULL
+		} else {
+			elements[i] = &object.String{Value: s[start:match[2*i+1]]}
+		}
+	}
+	return &object.Array{Elements: elements}
+}
+
+// regexReplace replaces every match; $1 or ${name} in the replacement
+// stand for the groups of the match, and $$ for a dollar sign.
+func regexReplace(env *object.Environment, args ...object.Object) object.Object {
+	values, err := stringArgs("replace", args[1:])
+	if err != nil {
+		return err
+	}
+	return &object.String{Value: args[0].(*object.Regex).Value.ReplaceAllString(values[0], values[1])}
+}
+
+// regexSplit splits the string around the matches, into at most n parts
+// if given.
+func regexSplit(env *object.Environment, args ...object.Object) object.Object {
+	s, err := stringArg("split", args[1])
+	if err != nil {
+		return err
+	}
+	n, err := limitArg("split", args, 2)
+	if err != nil {
+		return err
+	}
+	return stringArray(args[0].(*object.Regex).Value.Split(s, n))
+}
@@ -0,0 +1,56 @@
+package evaluator
+
+import (
+	"interpreter/ast"
+	"interpreter/object"
+	"testing"
+)
+
+func TestRegex(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`/b+/.match("abbc")`, true},
+		{`/^b/.match("abbc")`, false},
+		{`/ABC/i.match("xabcx")`, true},
+		{`regex("a.c", "s").match("a
+c")`, true},
+		{`regex("a/b").match("a/b")`, true},
+		{`/(\w+)=(\d+)?/.find("x; key= y")`, []interface{}{"key=", "key", nil}},
+		{`/\d+/.find("none")`, nil},
+		{`len(/\d/.find_all("a1b22c333"))`, 6},
+		{`/\d+/.find_all("a1b22c333", 2)[1][0]`, "22"},
+		{`/(\w+)@(\w+)/.replace("ann@host, bob@box", "$2:$1")`, "host:ann, box:bob"},
+		{`/(?P<user>\w+)@\w+/.replace("ann@host", "${user}$$")`, "ann$"},
+		{`/\s*,\s*/.split("a , b,c")`, []string{"a", "b", "c"}},
+		{`/,/.split("a,b,c", 2)`, []string{"a", "b,c"}},
+		{`/a/.match(1)`, "argument to `match` not supported, got INTEGER"},
+		{`/a/.split("a", "b")`, "argument to `split` not supported, got STRING"},
+		{`/a/.replace("a")`, "wrong number of arguments. got=1, want=2"},
+		{`/a/.nope`, "regex has no method nope"},
+		{`/a(/`, "invalid regex /a(/: missing closing ): `a(`"},
+		{`regex("[z-a]")`, "invalid regex /[z-a]/: invalid character class range: `z-a`"},
+		{`/a/x`, "invalid regex flag 'x', want one of imsU"},
+		{`regex(1)`, "argument to `regex` not supported, got INTEGER"},
+		{`8 / 2 / 2`, 2},
+	}
+	for _, tt := range tests {
+		testValue(t, tt.input, testEval(tt.input), tt.expected)
+	}
+
+	if got := testEval(`regex("a/b", "i")`).Inspect(); got != `/a\/b/i` {
+		t.Errorf("wrong Inspect. got=%q", got)
+	}
+}
+
+func TestRegexErrorPosition(t *testing.T) {
+	err, ok := testEval("let x = 1;\nlet re = /(/;").(*object.Error)
+	if !ok {
+		t.Fatalf("no error")
+	}
+	tok := ast.NodeToken(err.Node)
+	if _, ok := err.Node.(*ast.RegexLiteral); !ok || tok.Line != 2 || tok.Column != 10 {
+		t.Errorf("wrong error position. got=%T at %d:%d", err.Node, tok.Line, tok.Column)
+	}
+}
@@ -0,0 +1,300 @@
+package evaluator
+
+import (
+	"fmt"
+	"interpreter/object"
+	"strings"
+	"unicode/utf8"
+)
+
+// stringsModule is imported with `import "strings"`. Positions and
+// lengths count characters, not bytes.
+var stringsModule = &object.Module{Path: "strings", Exports: map[string]object.Object{
+	"split":       &object.Builtin{MinArgs: 2, MaxArgs: 2, Fn: stringsSplit},
+	"join":        &object.Builtin{MinArgs: 2, MaxArgs: 2, Fn: stringsJoin},
+	"trim":        &object.Builtin{MinArgs: 1, MaxArgs: 2, Fn: stringsTrim},
+	"upper":       stringsMap("upper", strings.ToUpper),
+	"lower":       stringsMap("lower", strings.ToLower),
+	"contains":    stringsTest("contains", strings.Contains),
+	"starts_with": stringsTest("starts_with", strings.HasPrefix),
+	"ends_with":   stringsTest("ends_with", strings.HasSuffix),
+	"index":       &object.Builtin{MinArgs: 2, MaxArgs: 2, Fn: stringsIndex},
+	"replace":     &object.Builtin{MinArgs: 3, MaxArgs: 4, Fn: stringsReplace},
+	"repeat":      &object.Builtin{MinArgs: 2, MaxArgs: 2, Fn: stringsRepeat},
+	"substr":      &object.Builtin{MinArgs: 2, MaxArgs: 3, Fn: stringsSubstr},
+	"chars":       &object.Builtin{MinArgs: 1, MaxArgs: 1, Fn: stringsChars},
+	"format":      &object.Builtin{MinArgs: 1, MaxArgs: -1, Fn: stringsFormat},
+}}
+
+// stringArg returns the value of a string argument of the builtin name.
+func stringArg(name string, arg object.Object) (string, *object.Error) {
+	s, ok := arg.(*object.String)
+	if !ok {
+		return "", newError("argument to `%s` not supported, got %s", name, arg.Type())
+	}
+	return s.Value, nil
+}
+
+// stringArgs returns the values of the string arguments of the builtin
+// name.
+func stringArgs(name string, args []object.Object) ([]string, *object.Error) {
+	values := make([]string, len(args))
+	for i, arg := range args {
+		s, err := stringArg(name, arg)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = s
+	}
+	return values, nil
+}
+
+func stringArray(values []string) *object.Array {
+	elements := make([]object.Object, len(values))
+	for i, s := range values {
+		elements[i] = &object.String{Value: s}
+	}
+	return &object.Array{Elements: elements}
+}
+
+// stringsMap makes a builtin transforming a string with fn.
+func stringsMap(name string, fn func(string) string) *object.Builtin {
+	return &object.Builtin{MinArgs: 1, MaxArgs: 1, Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		s, err := stringArg(name, args[0])
+		if err != nil {
+			return err
+		}
+		return &object.String{Value: fn(s)}
+	}}
+}
+
+// stringsTest makes a builtin testing two strings with fn.
+func stringsTest(name string, fn func(s, sub string) bool) *object.Builtin {
+	return &object.Builtin{MinArgs: 2, MaxArgs: 2, Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		values, err := stringArgs(name, args)
+		if err != nil {
+			return err
+		}
+		return nativeBooleanVariable(fn(values[0], values[1]))
+	}}
+}
+
+// stringsSplit splits around a separator, or into characters when the
+// separator is empty.
+func stringsSplit(env *object.Environment, args ...object.Object) object.Object {
+	values, err := stringArgs("split", args)
+	if err != nil {
+		return err
+	}
+	return stringArray(strings.Split(values[0], values[1]))
+}
+
+func stringsJoin(env *object.Environment, args ...object.Object) object.Object {
+	array, ok := args[0].(*object.Array)
+	if !ok {
+		return newError("argument to `join` not supported, got %s", args[0].Type())
+	}
+	sep, err := stringArg("join", args[1])
+	if err != nil {
+		return err
+	}
+	values := make([]string, len(array.Elements))
+	for i, el := range array.Elements {
+		s, ok := el.(*object.String)
+		if !ok {
+			return newError("element of `join` argument not supported, got %s", el.Type())
+		}
+		values[i] = s.Value
+	}
+	return &object.String{Value: strings.Join(values, sep)}
+}
+
+// stringsTrim removes white space, or the characters of its second
+// argument, from both ends.
+func stringsTrim(env *object.Environment, args ...object.Object) object.Object {
+	values, err := stringArgs("trim", args)
+	if err != nil {
+		return err
+	}
+	if len(values) == 1 {
+		return &object.String{Value: strings.TrimSpace(values[0])}
+	}
+	return &object.String{Value: strings.Trim(values[0], values[1])}
+}
+
+// stringsIndex returns the position of the first occurrence of a
+// substring, or -1.
+func stringsIndex(env *object.Environment, args ...object.Object) object.Object {
+	values, err := stringArgs("index", args)
+	if err != nil {
+		return err
+	}
+	i := strings.Index(values[0], values[1])
+	if i > 0 {
+		i = utf8.RuneCountInString(values[0][:i])
+	}
+	return &object.Integer{Value: int64(i)}
+}
+
+// stringsReplace replaces all occurrences of a substring, or only the
+// first n when n is given.
+func stringsReplace(env *object.Environment, args ...object.Object) object.Object {
+	values, err := stringArgs("replace", args[:3])
+	if err != nil {
+		return err
+	}
+	n := int64(-1)
+	if len(args) == 4 {
+		if n, err = intArg("replace", args[3]); err != nil {
+			return err
+		}
+	}
+	return &object.String{Value: strings.Replace(values[0], values[1], values[2], int(n))}
+}
+
+// MAX_REPEAT_LENGTH is the longest string, in bytes, `repeat` builds.
+const MAX_REPEAT_LENGTH = 1 << 28
+
+func stringsRepeat(env *object.Environment, args ...object.Object) object.Object {
+	s, err := stringArg("repeat", args[0])
+	if err != nil {
+		return err
+	}
+	n, err := intArg("repeat", args[1])
+	if err != nil {
+		return err
+	}
+	if n < 0 {
+		return newError("negative count in `repeat`: %d", n)
+	}
+	// divides rather than multiplies, as n * len(s) may overflow
+	if len(s) > 0 && n > MAX_REPEAT_LENGTH/int64(len(s)) {
+		return newError("`repeat` result too long: more than %d bytes", MAX_REPEAT_LENGTH)
+	}
+	return &object.String{Value: strings.Repeat(s, int(n))}
+}
+
+// stringsSubstr returns the characters from start, which counts from
+// the end when negative, up to the end or length characters. It is
+// s[start:start + length] without the need for start twice.
+func stringsSubstr(env *object.Environment, args ...object.Object) object.Object {
+	s, err := stringArg("substr", args[0])
+	if err != nil {
+		return err
+	}
+	runes := []rune(s)
+	start, err := sliceBound(args[1], len(runes), 0)
+	if err != nil {
+		return newError("argument to `substr` not supported, got %s", args[1].Type())
+	}
+	end := len(runes)
+	if len(args) == 3 {
+		length, err := intArg("substr", args[2])
+		if err != nil {
+			return err
+		}
+		if length < 0 {
+			return newError("negative length in `substr`: %d", length)
+		}
+		if int64(start)+length < int64(end) {
+			end = start + int(length)
+		}
+	}
+	return &object.String{Value: string(runes[start:end])}
+}
+
+func stringsChars(env *object.Environment, args ...object.Object) object.Object {
+	s, err := stringArg("chars", args[0])
+	if err != nil {
+		return err
+	}
+	return stringArray(strings.Split(s, ""))
+}
+
+// stringsFormat formats its arguments like Go's fmt.Sprintf. %v and %s
+// show any value as its Inspect text, %d and
+// %x take integers, %f, %e and %g numbers, %q strings and %t booleans.
+// Flags, width and precision work as in Go.
+func stringsFormat(env *object.Environment, args ...object.Object) object.Object {
+	format, err := stringArg("format", args[0])
+	if err != nil {
+		return err
+	}
+	args = args[1:]
+	var out strings.Builder
+	next := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			out.WriteByte(format[i])
+			continue
+		}
+		//the verb ends at the first letter, or at %
+		end := i + 1
+		for end < len(format) && strings.IndexByte("+-# 0123456789.", format[end]) >= 0 {
+			end++
+		}
+		if end == len(format) {
+			return newError("format: missing verb at end of %q", format)
+		}
+		spec, verb := format[i:end+1], format[end]
+		i = end
+		if verb == '%' {
+			out.WriteByte('%')
+			continue
+		}
+		if next == len(args) {
+			return newError("format: missing argument for %s", spec)
+		}
+		arg := args[next]
+		next++
+		value, err := formatValue(verb, arg)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&out, spec, value)
+	}
+	if next < len(args) {
+		return newError("format: %d arguments unused", len(args)-next)
+	}
+	return &object.String{Value: out.String()}
+}
+
+// formatValue returns the Go value printed for arg with verb.
+func formatValue(verb byte, arg object.Object) (interface{}, *object.Error) {
+	wrong := func() (interface{}, *object.Error) {
+		return nil, newError("format: %%%c not supported for %s", verb, arg.Type())
+	}
+	switch verb {
+	case 'v', 's':
+		return arg.Inspect(), nil
+	case 'q':
+		if s, ok := arg.(*object.String); ok {
+			return s.Value, nil
+		}
+		return wrong()
+	case 'c':
+		if i, ok := arg.(*object.Integer); ok && utf8.ValidRune(rune(i.Value)) && int64(rune(i.Value)) == i.Value {
+			return rune(i.Value), nil
+		}
+		return nil, newError("format: %%c needs an integer code point, got %s", object.InspectElement(arg))
+	case 'd', 'x', 'X', 'o', 'b':
+		switch i := arg.(type) {
+		case *object.Integer:
+			return i.Value, nil
+		case *object.BigInteger:
+			return i.Value, nil
+		}
+		return wrong()
+	case 'f', 'e', 'E', 'g', 'G':
+		if isNumber(arg) {
+			return toFloat(arg), nil
+		}
+		return wrong()
+	case 't':
+		if b, ok := arg.(*object.Boolean); ok {
+			return b.Value, nil
+		}
+		return wrong()
+	}
+	return nil, newError("format: unknown verb %%%c", verb)
+}
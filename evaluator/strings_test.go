@@ -0,0 +1,164 @@
+package evaluator
+
+import (
+	"interpreter/object"
+	"testing"
+)
+
+func TestStringsModule(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`strings.split("a,b,,c", ",")`, []string{"a", "b", "", "c"}},
+		{`strings.split("héllo", "")`, []string{"h", "é", "l", "l", "o"}},
+		{`strings.split("abc", 1)`, "argument to `split` not supported, got INTEGER"},
+		{`strings.join(["a", "b", "c"], "-")`, "a-b-c"},
+		{`strings.join([], "-")`, ""},
+		{`strings.join(["a", 1], "-")`, "element of `join` argument not supported, got INTEGER"},
+		{`strings.join("abc", "-")`, "argument to `join` not supported, got STRING"},
+		{`strings.trim("  hi  ")`, "hi"},
+		{`strings.trim("xxhixx", "x")`, "hi"},
+		{`strings.upper("héllo")`, "HÉLLO"},
+		{`strings.lower("ÀB")`, "àb"},
+		{`strings.upper(1)`, "argument to `upper` not supported, got INTEGER"},
+		{`strings.contains("seafood", "foo")`, true},
+		{`strings.contains("seafood", "bar")`, false},
+		{`strings.starts_with("golang", "go")`, true},
+		{`strings.ends_with("golang", "go")`, false},
+		{`strings.index("chicken", "ken")`, 4},
+		{`strings.index("héllo", "l")`, 2},
+		{`strings.index("chicken", "dmr")`, -1},
+		{`strings.replace("oink oink oink", "k", "ky")`, "oinky oinky oinky"},
+		{`strings.replace("oink oink oink", "oink", "moo", 2)`, "moo moo oink"},
+		{`strings.replace("a", "a")`, "wrong number of arguments. got=2, want=3 to 4"},
+		{`strings.repeat("ab", 3)`, "ababab"},
+		{`strings.repeat("ab", -1)`, "negative count in `repeat`: -1"},
+		{`strings.repeat("ab", 9223372036854775807)`, "`repeat` result too long: more than 268435456 bytes"},
+		{`strings.repeat("", 9223372036854775807)`, ""},
+		{`strings.substr("héllo", 1, 3)`, "éll"},
+		{`strings.substr("héllo", 3)`, "lo"},
+		{`strings.substr("héllo", -2, 1)`, "l"},
+		{`strings.substr("héllo", 2, 10)`, "llo"},
+		{`strings.substr("héllo", 9)`, ""},
+		{`strings.substr("héllo", "1")`, "argument to `substr` not supported, got STRING"},
+		{`strings.chars("añb")`, []string{"a", "ñ", "b"}},
+		{`strings.chars("")`, []string{}},
+		{`strings.format("%s is %d years", "Ann", 30)`, "Ann is 30 years"},
+		{`strings.format("%v %v %v", [1, "a"], true, 2.5)`, `[1, "a"] true 2.5`},
+		{`strings.format("%5.2f|%-4d|%x|%q|%%", 3.14159, 7, 255, "hi")`, ` 3.14|7   |ff|"hi"|%`},
+		{`strings.format("%d", "a")`, "format: %d not supported for STRING"},
+		{`strings.format("%c%c", 72, 0x1F600)`, "H\U0001F600"},
+		{`strings.format("%c", 2 ** 64)`, "format: %c needs an integer code point, got 18446744073709551616"},
+		{`strings.format("%c", 0x110000)`, "format: %c needs an integer code point, got 1114112"},
+		{`strings.format("%c", -1)`, "format: %c needs an integer code point, got -1"},
+		{`strings.format("%c", 4294967368)`, "format: %c needs an integer code point, got 4294967368"},
+		{`strings.format("%c", "a")`, `format: %c needs an integer code point, got "a"`},
+		{`strings.format("%d %d", 1)`, "format: missing argument for %d"},
+		{`strings.format("%d", 1, 2)`, "format: 1 arguments unused"},
+		{`strings.format("%y", 1)`, "format: unknown verb %y"},
+		{`strings.format("100%")`, `format: missing verb at end of "100%"`},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(`let strings = import "strings"; ` + tt.input)
+		testValue(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+func TestArrays(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`[1, 2 * 2, "three"][1]`, 4},
+		{`[1, 2, 3][-1]`, 3},
+		{`[1, 2, 3][3]`, nil},
+		{`[1, 2, 3][-4]`, nil},
+		{`let a = [1, 2, 3]; a[0] + a[1] + a[2]`, 6},
+		{`[1, 2]["a"]`, "index operator not supported: ARRAY[STRING]"},
+		{`[1, foo]`, "identifier not found: foo"},
+		{`len([1, 2, 3])`, 3},
+		{`len([])`, 0},
+		{`len("héllo")`, 5},
+		{`"héllo"[1]`, "é"},
+		{`"héllo"[-1]`, "o"},
+		{`"abc"[5]`, nil},
+		{`"héllo"[1:3]`, "él"},
+		{`"hello"[:2]`, "he"},
+		{`"hello"[3:]`, "lo"},
+		{`"hello"[-3:-1]`, "ll"},
+		{`"hello"[4:2]`, ""},
+		{`"hello"[-10:10]`, "hello"},
+		{`[1, 2, 3, 4][1:3]`, []int{2, 3}},
+		{`[1, 2, 3][:]`, []int{1, 2, 3}},
+		{`[1, 2, 3]["a":]`, "slice bound must be INTEGER, got STRING"},
+		{`5[1:]`, "slice operator not supported: INTEGER"},
+	}
+	for _, tt := range tests {
+		testValue(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestArrayInspect(t *testing.T) {
+	expected := `[1, "two", [3.5, true]]`
+	if got := testEval(`[1, "two", [3.5, true]]`).Inspect(); got != expected {
+		t.Errorf("wrong Inspect. expected=%q, got=%q", expected, got)
+	}
+}
+
+// testValue checks an evaluated value against an expected Go value: an
+// int, a bool, a string for a string, or for an error when the result
+// is an error, a slice for an array, or nil for NULL.
+func testValue(t *testing.T, input string, obj object.Object, expected interface{}) {
+	t.Helper()
+	switch expected := expected.(type) {
+	case nil:
+		testNullObject(t, obj)
+	case int:
+		testIntegerObject(t, obj, int64(expected))
+	case bool:
+		testBooleanObject(t, obj, expected)
+	case string:
+		switch obj := obj.(type) {
+		case *object.Error:
+			if obj.Message != expected {
+				t.Errorf("%s: wrong error message. expected=%q, got=%q", input, expected, obj.Message)
+			}
+		case *object.String:
+			if obj.Value != expected {
+				t.Errorf("%s: wrong string. expected=%q, got=%q", input, expected, obj.Value)
+			}
+		default:
+			t.Errorf("%s: object is not String or Error. got=%T (%+v)", input, obj, obj)
+		}
+	case []string:
+		array, ok := obj.(*object.Array)
+		if !ok || len(array.Elements) != len(expected) {
+			t.Errorf("%s: wrong array. got=%T (%+v)", input, obj, obj)
+			return
+		}
+		for i, el := range expected {
+			testValue(t, input, array.Elements[i], el)
+		}
+	case []interface{}:
+		array, ok := obj.(*object.Array)
+		if !ok || len(array.Elements) != len(expected) {
+			t.Errorf("%s: wrong array. got=%T (%+v)", input, obj, obj)
+			return
+		}
+		for i, el := range expected {
+			testValue(t, input, array.Elements[i], el)
+		}
+	case []int:
+		array, ok := obj.(*object.Array)
+		if !ok || len(array.Elements) != len(expected) {
+			t.Errorf("%s: wrong array. got=%T (%+v)", input, obj, obj)
+			return
+		}
+		for i, el := range expected {
+			testIntegerObject(t, array.Elements[i], int64(el))
+		}
+	default:
+		t.Fatalf("%s: unsupported expected value %T", input, expected)
+	}
+}
@@ -0,0 +1,170 @@
+package evaluator
+
+import (
+	"interpreter/object"
+	"math"
+	"time"
+)
+
+// DEFAULT_LAYOUT is the layout of format_time and parse_time when none is
+// given; layouts are written as Go's reference time.
+const DEFAULT_LAYOUT = time.RFC3339
+
+func timeNow(env *object.Environment, args ...object.Object) object.Object {
+	return &object.Time{Value: env.Runtime().Clock.Now()}
+}
+
+// timeUnix returns the seconds elapsed since 1970-01-01 UTC until the
+// given time, or now.
+func timeUnix(env *object.Environment, args ...object.Object) object.Object {
+	t := env.Runtime().Clock.Now()
+	if len(args) == 1 {
+		arg, ok := args[0].(*object.Time)
+		if !ok {
+			return newError("argument to `unix` not supported, got %s", args[0].Type())
+		}
+		t = arg.Value
+	}
+	return &object.Integer{Value: t.Unix()}
+}
+
+// timeSleep waits for a number of milliseconds, unless the evaluation is
+// canceled first.
+func timeSleep(env *object.Environment, args ...object.Object) object.Object {
+	ms, err := intArg("sleep", args[0])
+	if err != nil {
+		return err
+	}
+	if ms < 0 {
+		return newError("negative duration in `sleep`: %d", ms)
+	}
+	d, err := durationValue(args[0])
+	if err != nil {
+		return err
+	}
+	rt := env.Runtime()
+	after := rt.Clock.After(d)
+	if rt.Context == nil {
+		<-after
+		return NULL
+	}
+	select {
+	case <-after:
+		return NULL
+	case <-rt.Context.Done():
+		return canceled(env)
+	}
+}
+
+func formatTime(env *object.Environment, args ...object.Object) object.Object {
+	t, ok := args[0].(*object.Time)
+	if !ok {
+		return newError("argument to `format_time` not supported, got %s", args[0].Type())
+	}
+	layout, err := layoutArg("format_time", args)
+	if err != nil {
+		return err
+	}
+	return &object.String{Value: t.Value.Format(layout)}
+}
+
+func parseTime(env *object.Environment, args ...object.Object) object.Object {
+	s, err := stringArg("parse_time", args[0])
+	if err != nil {
+		return err
+	}
+	layout, err := layoutArg("parse_time", args)
+	if err != nil {
+		return err
+	}
+	t, parseErr := time.Parse(layout, s)
+	if parseErr != nil {
+		return newError("parse_time: %s", parseErr)
+	}
+	return &object.Time{Value: t}
+}
+
+func layoutArg(name string, args []object.Object) (string, *object.Error) {
+	if len(args) < 2 {
+		return DEFAULT_LAYOUT, nil
+	}
+	return stringArg(name, args[1])
+}
+
+// duration returns the milliseconds of a duration such as "1h30m".
+func duration(env *object.Environment, args ...object.Object) object.Object {
+	s, err := stringArg("duration", args[0])
+	if err != nil {
+		return err
+	}
+	d, parseErr := time.ParseDuration(s)
+	if parseErr != nil {
+		return newError("duration: %s", parseErr)
+	}
+	return &object.Integer{Value: d.Milliseconds()}
+}
+
+// evalTimeInfixExpression adds milliseconds to and subtracts them from
+// times, subtracts times from each other, giving milliseconds, and
+// compares them.
+func evalTimeInfixExpression(left object.Object, operator string, right object.Object) object.Object {
+	lt, lok := left.(*object.Time)
+	rt, rok := right.(*object.Time)
+	switch {
+	case lok && rok:
+		switch operator {
+		case "-":
+			//Sub saturates when the difference does not fit a Duration
+			d := lt.Value.Sub(rt.Value)
+			if !rt.Value.Add(d).Equal(lt.Value) {
+				return newError("time difference out of range: %s - %s", lt.Inspect(), rt.Inspect())
+			}
+			return &object.Integer{Value: d.Milliseconds()}
+		case "<":
+			return nativeBooleanVariable(lt.Value.Before(rt.Value))
+		case ">":
+			return nativeBooleanVariable(lt.Value.After(rt.Value))
+		case "==":
+			return nativeBooleanVariable(lt.Value.Equal(rt.Value))
+		case "!=":
+			return nativeBooleanVariable(!lt.Value.Equal(rt.Value))
+		}
+	case lok && right.Type() == object.INTEGER_OBJ && (operator == "+" || operator == "-"):
+		d, err := durationValue(right)
+		if err != nil {
+			return err
+		}
+		if operator == "-" {
+			d = -d
+		}
+		return &object.Time{Value: lt.Value.Add(d)}
+	case rok && left.Type() == object.INTEGER_OBJ && operator == "+":
+		d, err := durationValue(left)
+		if err != nil {
+			return err
+		}
+		return &object.Time{Value: rt.Value.Add(d)}
+	}
+	switch {
+	case left.Type() != right.Type() && operator == "==":
+		return FALSE
+	case left.Type() != right.Type() && operator == "!=":
+		return TRUE
+	case left.Type() != right.Type():
+		return newError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
+	}
+	return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+}
+
+// MAX_DURATION is the largest number of milliseconds a time.Duration
+// holds, about 292 years.
+const MAX_DURATION = math.MaxInt64 / int64(time.Millisecond)
+
+// durationValue converts a number of milliseconds to a time.Duration,
+// which must hold it.
+func durationValue(obj object.Object) (time.Duration, *object.Error) {
+	if i, ok := obj.(*object.Integer); ok && -MAX_DURATION <= i.Value && i.Value <= MAX_DURATION {
+		return time.Duration(i.Value) * time.Millisecond, nil
+	}
+	return 0, newError("duration out of range: %s", obj.Inspect())
+}
@@ -0,0 +1,113 @@
+package evaluator
+
+import (
+	"context"
+	"interpreter/lexer"
+	"interpreter/object"
+	"interpreter/parser"
+	"testing"
+	"time"
+)
+
+// fakeClock starts at a fixed time and moves forward only when slept on.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.now = c.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+func evalTime(input string) object.Object {
+	env := object.NewEnvironment()
+	env.Runtime().Clock = &fakeClock{now: time.Date(2024, 2, 29, 12, 30, 0, 0, time.UTC)}
+	return Eval(parser.New(lexer.New(input)).ParseProgram(), env)
+}
+
+func TestTime(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`format_time(now())`, "2024-02-29T12:30:00Z"},
+		{`unix()`, 1709209800},
+		{`unix(parse_time("1970-01-02", "2006-01-02"))`, 86400},
+		{`let t = now(); sleep(1500); now() - t`, 1500},
+		{`format_time(now() + duration("36h"), "Mon 2 Jan 15:04")`, "Sat 2 Mar 00:30"},
+		{`format_time(1000 + now() - 61000, "15:04:05")`, "12:29:00"},
+		{`let t = now(); t + 1 > t`, true},
+		{`let t = now(); t - 1 < t`, true},
+		{`parse_time("2024-02-29T12:30:00Z") == now()`, true},
+		{`now() == 1`, false},
+		{`now() != 1`, true},
+		{`duration("1h30m")`, 5400000},
+		{`duration("1.5s") + 1`, 1501},
+		{`now() * 2`, "type mismatch: TIME * INTEGER"},
+		{`now() + now()`, "unknown operator: TIME + TIME"},
+		{`now() + "1"`, "type mismatch: TIME + STRING"},
+		{`sleep(-1)`, "negative duration in `sleep`: -1"},
+		{`sleep("1")`, "argument to `sleep` not supported, got STRING"},
+		{`sleep(9223372036854775807)`, "duration out of range: 9223372036854775807"},
+		{`parse_time("2020-01-01T00:00:00Z") + 9223372036854775807`, "duration out of range: 9223372036854775807"},
+		{`now() - 10000000000000`, "duration out of range: 10000000000000"},
+		{`10000000000000 + now()`, "duration out of range: 10000000000000"},
+		{`parse_time("2400-01-01T00:00:00Z") - parse_time("2000-01-01T00:00:00Z")`,
+			"time difference out of range: 2400-01-01T00:00:00Z - 2000-01-01T00:00:00Z"},
+		{`parse_time("2000-01-01T00:00:00Z") - parse_time("2400-01-01T00:00:00Z")`,
+			"time difference out of range: 2000-01-01T00:00:00Z - 2400-01-01T00:00:00Z"},
+		{`parse_time("2200-01-01T00:00:00Z") - parse_time("2000-01-01T00:00:00Z")`, 6311433600000},
+		{`format_time(parse_time("2020-01-01T00:00:00Z") + 9223372036854, "2006")`, "2312"},
+		{`format_time(parse_time("2020-01-01T00:00:00Z") - 9223372036854, "2006")`, "1727"},
+		{`parse_time("29/02/2024", "2006-01-02")`, `parse_time: parsing time "29/02/2024" as "2006-01-02": cannot parse "29/02/2024" as "2006"`},
+		{`duration("soon")`, `duration: time: invalid duration "soon"`},
+		{`format_time(1)`, "argument to `format_time` not supported, got INTEGER"},
+	}
+	for _, tt := range tests {
+		testValue(t, tt.input, evalTime(tt.input), tt.expected)
+	}
+
+	if got := evalTime(`now() + 250`).Inspect(); got != "2024-02-29T12:30:00.25Z" {
+		t.Errorf("wrong Inspect. got=%q", got)
+	}
+}
+
+// stuckClock never wakes sleepers, and tells when one starts to sleep.
+type stuckClock struct {
+	sleeping chan bool
+}
+
+func (c stuckClock) Now() time.Time { return time.Time{} }
+
+func (c stuckClock) After(d time.Duration) <-chan time.Time {
+	c.sleeping <- true
+	return nil
+}
+
+func TestCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	env := object.NewEnvironment()
+	env.Runtime().Context = ctx
+	clock := stuckClock{make(chan bool)}
+	env.Runtime().Clock = clock
+
+	done := make(chan object.Object)
+	go func() {
+		done <- Eval(parser.New(lexer.New("sleep(1); echo(1)")).ParseProgram(), env)
+	}()
+	<-clock.sleeping
+	cancel()
+	select {
+	case result := <-done:
+		testValue(t, "sleep", result, "interrupted: context canceled")
+	case <-time.After(5 * time.Second):
+		t.Fatal("sleep not interrupted")
+	}
+
+	input := "let f = fn(n) { f(n + 1) }; f(0)"
+	testValue(t, input, Eval(parser.New(lexer.New(input)).ParseProgram(), env), "interrupted: context canceled")
+}
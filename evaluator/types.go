@@ -0,0 +1,102 @@
+package evaluator
+
+import (
+	"interpreter/object"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// typeName returns the type of a value, as error messages show it.
+func typeName(env *object.Environment, args ...object.Object) object.Object {
+	return &object.String{Value: string(args[0].Type())}
+}
+
+// typePredicate makes a builtin reporting whether its argument has one
+// of the given types.
+func typePredicate(types ...object.ObjectType) *object.Builtin {
+	return &object.Builtin{MinArgs: 1, MaxArgs: 1, Fn: func(env *object.Environment, args ...object.Object) object.Object {
+		for _, t := range types {
+			if args[0].Type() == t {
+				return TRUE
+			}
+		}
+		return FALSE
+	}}
+}
+
+// toString returns strings as they are and the Inspect of other values.
+func toString(env *object.Environment, args ...object.Object) object.Object {
+	if s, ok := args[0].(*object.String); ok {
+		return s
+	}
+	return &object.String{Value: args[0].Inspect()}
+}
+
+// toInt converts numbers, truncating floats towards zero, booleans and
+// strings holding a decimal integer.
+func toInt(env *object.Environment, args ...object.Object) object.Object {
+	switch arg := args[0].(type) {
+	case *object.Integer, *object.BigInteger:
+		return arg
+	case *object.Float:
+		i, ok := integerFromFloat(arg.Value)
+		if !ok {
+			return newError("int: %s out of range", arg.Inspect())
+		}
+		return i
+	case *object.Boolean:
+		if arg.Value {
+			return &object.Integer{Value: 1}
+		}
+		return &object.Integer{Value: 0}
+	case *object.String:
+		s := strings.TrimSpace(arg.Value)
+		i, err := strconv.ParseInt(s, 10, 64)
+		if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+			b, _ := new(big.Int).SetString(s, 10)
+			return integerObject(b)
+		}
+		if err != nil {
+			return conversionError("int", arg.Value, object.INTEGER_OBJ, err)
+		}
+		return &object.Integer{Value: i}
+	default:
+		return newError("argument to `int` not supported, got %s", args[0].Type())
+	}
+}
+
+// toFloatBuiltin converts numbers and strings holding a decimal number.
+func toFloatBuiltin(env *object.Environment, args ...object.Object) object.Object {
+	switch arg := args[0].(type) {
+	case *object.Integer, *object.BigInteger:
+		return &object.Float{Value: toFloat(arg)}
+	case *object.Float:
+		return arg
+	case *object.String:
+		f, err := strconv.ParseFloat(strings.TrimSpace(arg.Value), 64)
+		if err != nil {
+			return conversionError("float", arg.Value, object.FLOAT_OBJ, err)
+		}
+		return &object.Float{Value: f}
+	default:
+		return newError("argument to `float` not supported, got %s", args[0].Type())
+	}
+}
+
+func conversionError(name, s string, to object.ObjectType, err error) *object.Error {
+	if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+		return newError("%s: %q out of range", name, s)
+	}
+	return newError("%s: cannot parse %q as %s", name, s, to)
+}
+
+// toBool converts a value to its truthiness.
+func toBool(env *object.Environment, args ...object.Object) object.Object {
+	return nativeBooleanVariable(isTrue(args[0]))
+}
+
+// inspect shows a value as the REPL does, with strings quoted.
+func inspect(env *object.Environment, args ...object.Object) object.Object {
+	return &object.String{Value: object.InspectElement(args[0])}
+}
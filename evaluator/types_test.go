@@ -0,0 +1,72 @@
+package evaluator
+
+import "testing"
+
+func TestTypeBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`type(1)`, "INTEGER"},
+		{`type(1.5)`, "FLOAT"},
+		{`type("a")`, "STRING"},
+		{`type([1][5])`, "NULL"},
+		{`type({})`, "HASH"},
+		{`type(fn() {})`, "FUNCTION"},
+		{`type(len)`, "BUILTIN"},
+		{`type(/a/)`, "REGEX"},
+		{`let f = fn() {}; type(f())`, "NULL"},
+		{`is_int(1)`, true},
+		{`is_int(1.0)`, false},
+		{`is_number(1.0)`, true},
+		{`is_string("1")`, true},
+		{`is_bool(false)`, true},
+		{`is_null([][0])`, true},
+		{`let f = fn() { let x = 1; }; is_null(f())`, true},
+		{`is_array([])`, true},
+		{`is_hash([])`, false},
+		{`is_fn(fn(x) { x })`, true},
+		{`is_fn(len)`, true},
+		{`is_fn("len")`, false},
+		{`str(12)`, "12"},
+		{`str("a")`, "a"},
+		{`str(2.0)`, "2.0"},
+		{`str(["a", 1])`, `["a", 1]`},
+		{`let f = fn() {}; str(f())`, "null"},
+		{`let f = fn() {}; bool(f())`, false},
+		{`int("42")`, 42},
+		{`int(" -7 ")`, -7},
+		{`int(3.9)`, 3},
+		{`int(-3.9)`, -3},
+		{`int(true)`, 1},
+		{`int("abc")`, `int: cannot parse "abc" as INTEGER`},
+		{`int("1.5")`, `int: cannot parse "1.5" as INTEGER`},
+		{`int(0.0 / 0)`, "int: NaN out of range"},
+		{`int([])`, "argument to `int` not supported, got ARRAY"},
+		{`float("x")`, `float: cannot parse "x" as FLOAT`},
+		{`float(true)`, "argument to `float` not supported, got BOOLEAN"},
+		{`bool(0)`, true},
+		{`bool("")`, true},
+		{`bool([])`, true},
+		{`bool(false)`, false},
+		{`bool([1][3])`, false},
+		{`!bool(1)`, false},
+		{`inspect("a")`, `"a"`},
+		{`inspect(1)`, "1"},
+		{`inspect({"k": "v"})`, `{"k": "v"}`},
+	}
+	for _, tt := range tests {
+		testValue(t, tt.input, testEval(tt.input), tt.expected)
+	}
+
+	floats := map[string]string{
+		`float(2)`:       "2.0",
+		`float(" 1.5 ")`: "1.5",
+		`float(-3)`:      "-3.0",
+	}
+	for input, expected := range floats {
+		if got := testEval(input).Inspect(); got != expected {
+			t.Errorf("%s: expected %s, got %s", input, expected, got)
+		}
+	}
+}
@@ -0,0 +1,341 @@
+// Package format prints programs in the canonical source style:
+// one statement per line, tab indentation, every statement terminated
+// by a semicolon and only the parentheses the grammar requires.
+package format
+
+import (
+	"bytes"
+	"errors"
+	"interpreter/ast"
+	"interpreter/lexer"
+	"interpreter/parser"
+	"interpreter/token"
+	"math"
+	"strings"
+)
+
+// Source formats src, keeping its comments and single blank lines
+// between statements. Source that does not parse is returned unchanged
+// together with the parser errors.
+func Source(src []byte) ([]byte, error) {
+	l := lexer.New(string(src))
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return src, errors.New(strings.Join(p.Errors(), "\n"))
+	}
+
+	pr := &printer{comments: l.Comments()}
+	pr.statements(program.Statements, math.MaxInt32)
+	return pr.out.Bytes(), nil
+}
+
+// Node formats a single node. Comments are not part of the AST, so
+// they are lost.
+func Node(node ast.Node) string {
+	pr := &printer{}
+	switch node := node.(type) {
+	case *ast.Program:
+		pr.statements(node.Statements, math.MaxInt32)
+	case ast.Statement:
+		pr.statement(node)
+	case ast.Expression:
+		pr.expression(node, parser.LOWEST)
+	}
+	return pr.out.String()
+}
+
+type printer struct {
+	out      bytes.Buffer
+	indent   int
+	comments []token.Token //comments not printed yet
+	lastLine int           //source line of the last statement or comment printed
+}
+
+//prints one statement per line, with the comments in front of each
+//statement and the comments left before the closing line of the block
+func (p *printer) statements(list []ast.Statement, closingLine int) {
+	first := true
+	for i, stmt := range list {
+		start, end := lineRange(stmt)
+		p.leadingComments(start, &first)
+		p.separate(start, &first)
+		p.writeIndent()
+		p.statement(stmt)
+		var next token.Token
+		if i+1 < len(list) {
+			next, _ = ast.Span(list[i+1])
+		}
+		p.trailingComments(end, next)
+		p.out.WriteString("\n")
+		p.lastLine = end
+	}
+	p.leadingComments(closingLine, &first)
+}
+
+func (p *printer) leadingComments(before int, first *bool) {
+	for len(p.comments) > 0 && p.comments[0].Line < before {
+		c := p.comments[0]
+		p.comments = p.comments[1:]
+		p.separate(c.Line, first)
+		p.writeIndent()
+		p.out.WriteString(c.Literal + "\n")
+		p.lastLine = c.Line
+	}
+}
+
+//prints after a statement or a match arm the comments up to its last
+//line: those inside expressions printed on one line, and the one that
+//follows it, unless the token next comes first on that line
+func (p *printer) trailingComments(end int, next token.Token) {
+	for len(p.comments) > 0 && p.comments[0].Line <= end {
+		c := p.comments[0]
+		if next.Line == c.Line && next.Column < c.Column {
+			return
+		}
+		p.out.WriteString(" " + c.Literal)
+		p.comments = p.comments[1:]
+	}
+}
+
+//keeps one blank line where the source had at least one
+func (p *printer) separate(line int, first *bool) {
+	if !*first && line > p.lastLine+1 {
+		p.out.WriteString("\n")
+	}
+	*first = false
+}
+
+func (p *printer) writeIndent() {
+	p.out.WriteString(strings.Repeat("\t", p.indent))
+}
+
+func (p *printer) statement(stmt ast.Statement) {
+	switch stmt := stmt.(type) {
+	case *ast.LetStatement:
+		p.out.WriteString("let " + stmt.Name.Value + " = ")
+		p.expression(stmt.Value, parser.LOWEST)
+	case *ast.ReturnStatement:
+		p.out.WriteString("return ")
+		p.expression(stmt.ReturnValue, parser.LOWEST)
+	case *ast.ExportStatement:
+		p.out.WriteString("export ")
+		p.statement(stmt.Statement)
+		return
+	case *ast.ExpressionStatement:
+		p.expression(stmt.Expression, parser.LOWEST)
+	case *ast.BlockStatement:
+		p.block(stmt)
+		return
+	}
+	p.out.WriteString(";")
+}
+
+func (p *printer) block(block *ast.BlockStatement) {
+	if len(block.Statements) == 0 && !p.hasCommentBefore(block.Rbrace.Line) {
+		p.out.WriteString("{}")
+		return
+	}
+	p.out.WriteString("{\n")
+	p.indent++
+	p.lastLine = block.Token.Line
+	p.statements(block.Statements, block.Rbrace.Line)
+	p.indent--
+	p.writeIndent()
+	p.out.WriteString("}")
+	p.lastLine = block.Rbrace.Line
+}
+
+func (p *printer) hasCommentBefore(line int) bool {
+	return len(p.comments) > 0 && p.comments[0].Line < line
+}
+
+//prints exp, wrapped in parentheses if it binds less tightly than
+//the surrounding precedence requires
+func (p *printer) expression(exp ast.Expression, precedence int) {
+	if exp == nil {
+		return
+	}
+	if precedenceOf(exp) < precedence {
+		p.out.WriteString("(")
+		defer p.out.WriteString(")")
+	}
+
+	switch exp := exp.(type) {
+	case *ast.Identifier:
+		p.out.WriteString(exp.Value)
+	case *ast.IntegerLiteral, *ast.FloatLiteral, *ast.RegexLiteral, *ast.Boolean, *ast.NullLiteral:
+		p.out.WriteString(exp.TokenLiteral())
+	case *ast.StringLiteral:
+		p.out.WriteString(`"` + exp.Value + `"`)
+	case *ast.PrefixExpression:
+		p.out.WriteString(exp.Operator)
+		p.expression(exp.Right, parser.PREFIX)
+	case *ast.InfixExpression:
+		// operators are left-associative, except **, so an operand of
+		// the same precedence only needs parentheses on the other side
+		prec := parser.Precedence(exp.Token.Type)
+		left, right := prec, prec+1
+		if parser.RightAssociative(exp.Token.Type) {
+			left, right = prec+1, prec
+		}
+		p.expression(exp.Left, left)
+		p.out.WriteString(" " + exp.Operator + " ")
+		p.expression(exp.Right, right)
+	case *ast.IfExpression:
+		p.out.WriteString("if (")
+		p.expression(exp.Condition, parser.LOWEST)
+		p.out.WriteString(") ")
+		p.block(exp.Consequence)
+		if exp.Alternative != nil {
+			p.out.WriteString(" else ")
+			p.block(exp.Alternative)
+		}
+	case *ast.ConditionalExpression:
+		p.expression(exp.Condition, parser.CONDITIONAL+1)
+		p.out.WriteString(" ? ")
+		p.expression(exp.Consequence, parser.LOWEST)
+		p.out.WriteString(" : ")
+		p.expression(exp.Alternative, parser.CONDITIONAL)
+	case *ast.MatchExpression:
+		p.match(exp)
+	case *ast.FunctionLiteral:
+		params := []string{}
+		for _, param := range exp.Parameters {
+			params = append(params, param.Value)
+		}
+		p.out.WriteString("fn(" + strings.Join(params, ", ") + ") ")
+		p.block(exp.Body)
+	// calls, indexing and member access chain from left to right,
+	// so any of them can be the operand of another without parentheses
+	case *ast.CallExpression:
+		p.expression(exp.Function, parser.CALL)
+		p.optional(exp.Optional)
+		p.out.WriteString("(")
+		for i, arg := range exp.Arguments {
+			if i > 0 {
+				p.out.WriteString(", ")
+			}
+			p.expression(arg, parser.LOWEST)
+		}
+		p.out.WriteString(")")
+	case *ast.IndexExpression:
+		p.expression(exp.Left, parser.CALL)
+		p.optional(exp.Optional)
+		p.out.WriteString("[")
+		p.expression(exp.Index, parser.LOWEST)
+		p.out.WriteString("]")
+	case *ast.SliceExpression:
+		p.expression(exp.Left, parser.CALL)
+		p.optional(exp.Optional)
+		p.out.WriteString("[")
+		p.expression(exp.Low, parser.LOWEST)
+		p.out.WriteString(":")
+		p.expression(exp.High, parser.LOWEST)
+		p.out.WriteString("]")
+	case *ast.ArrayLiteral:
+		p.out.WriteString("[")
+		for i, el := range exp.Elements {
+			if i > 0 {
+				p.out.WriteString(", ")
+			}
+			p.expression(el, parser.LOWEST)
+		}
+		p.out.WriteString("]")
+	case *ast.HashLiteral:
+		p.out.WriteString("{")
+		for i, key := range exp.Keys {
+			if i > 0 {
+				p.out.WriteString(", ")
+			}
+			p.expression(key, parser.LOWEST)
+			p.out.WriteString(": ")
+			p.expression(exp.Values[i], parser.LOWEST)
+		}
+		p.out.WriteString("}")
+	case *ast.MemberExpression:
+		p.expression(exp.Object, parser.CALL)
+		if exp.Optional {
+			p.out.WriteString("?." + exp.Property.Value)
+		} else {
+			p.out.WriteString("." + exp.Property.Value)
+		}
+	case *ast.ImportExpression:
+		p.out.WriteString(`import "` + exp.Path.Value + `"`)
+	default:
+		p.out.WriteString(exp.String())
+	}
+}
+
+//prints one arm per line, each followed by a comma
+func (p *printer) match(me *ast.MatchExpression) {
+	p.out.WriteString("match (")
+	p.expression(me.Value, parser.LOWEST)
+	if len(me.Arms) == 0 {
+		p.out.WriteString(") {}")
+		return
+	}
+	p.out.WriteString(") {\n")
+	p.indent++
+	for _, arm := range me.Arms {
+		for len(p.comments) > 0 && p.comments[0].Line < arm.Token.Line {
+			p.writeIndent()
+			p.out.WriteString(p.comments[0].Literal + "\n")
+			p.comments = p.comments[1:]
+		}
+		p.writeIndent()
+		for i, pattern := range arm.Patterns {
+			if i > 0 {
+				p.out.WriteString(" | ")
+			}
+			p.expression(pattern, parser.LOWEST)
+		}
+		if len(arm.Patterns) == 0 {
+			p.out.WriteString("_")
+		}
+		if arm.Guard != nil {
+			p.out.WriteString(" if ")
+			p.expression(arm.Guard, parser.LOWEST)
+		}
+		p.out.WriteString(" => ")
+		p.expression(arm.Body, parser.LOWEST)
+		p.out.WriteString(",")
+		_, last := ast.Span(arm.Body)
+		p.trailingComments(last.Line, me.Rbrace)
+		p.out.WriteString("\n")
+	}
+	p.indent--
+	p.writeIndent()
+	p.out.WriteString("}")
+}
+
+//writes the "?." of optional calls and indexes
+func (p *printer) optional(ok bool) {
+	if ok {
+		p.out.WriteString("?.")
+	}
+}
+
+//the binding power of the operator at the root of exp
+func precedenceOf(exp ast.Expression) int {
+	switch exp := exp.(type) {
+	case *ast.InfixExpression:
+		return parser.Precedence(exp.Token.Type)
+	case *ast.ConditionalExpression:
+		return parser.CONDITIONAL
+	case *ast.PrefixExpression:
+		return parser.PREFIX
+	case *ast.CallExpression:
+		return parser.CALL
+	case *ast.IndexExpression, *ast.SliceExpression, *ast.MemberExpression:
+		return parser.INDEX
+	default:
+		return parser.INDEX + 1
+	}
+}
+
+//the first and last source lines spanned by node
+func lineRange(node ast.Node) (int, int) {
+	first, last := ast.Span(node)
+	return first.Line, last.Line
+}
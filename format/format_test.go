@@ -0,0 +1,220 @@
+package format
+
+import (
+	"interpreter/lexer"
+	"interpreter/parser"
+	"testing"
+)
+
+func TestSource(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x=1+2*3", "let x = 1 + 2 * 3;\n"},
+		{"let x = (1 + 2) * 3;", "let x = (1 + 2) * 3;\n"},
+		{"((a + b) + c)", "a + b + c;\n"},
+		{"a + (b + c)", "a + (b + c);\n"},
+		{"a - (b - c) * d", "a - (b - c) * d;\n"},
+		{"(a < b) == (c > d)", "a < b == c > d;\n"},
+		{"-(a + b) * !c", "-(a + b) * !c;\n"},
+		{"(f(1))(2)[3].y", "f(1)(2)[3].y;\n"},
+		{"(a + b)(c)", "(a + b)(c);\n"},
+		{"(-a).b", "(-a).b;\n"},
+		{`let m = import "lib"; m["x"]`, "let m = import \"lib\";\nm[\"x\"];\n"},
+		{"export let a = 1", "export let a = 1;\n"},
+		{"[1,2*3,[ ]][0]", "[1, 2 * 3, []][0];\n"},
+		{"/a\\/b+/i.split(x/ /,/)", "/a\\/b+/i.split(x / /,/);\n"},
+		{`{ "a":1,2:{ } }["a"]`, "{\"a\": 1, 2: {}}[\"a\"];\n"},
+		{"s[1 : n+1]; s[:2]; s[ 2: ]; s[:]", "s[1:n + 1];\ns[:2];\ns[2:];\ns[:];\n"},
+		{"(-a)[1:]", "(-a)[1:];\n"},
+		{"a??(b??null)", "a ?? (b ?? null);\n"},
+		{"(a ** b) ** (c ** d)", "(a ** b) ** c ** d;\n"},
+		{"-(a ** b); (-a) ** b", "-a ** b;\n(-a) ** b;\n"},
+		{"(a | b) & c; a | (b & c)", "(a | b) & c;\na | b & c;\n"},
+		{"a<<1%~b", "a << 1 % ~b;\n"},
+		{"(a ?? b) == c", "(a ?? b) == c;\n"},
+		{"f ?.(1)?.[k] ?.x?.[1:]", "f?.(1)?.[k]?.x?.[1:];\n"},
+		{"a?b:c?d:e; (a?b:c)?d:e", "a ? b : c ? d : e;\n(a ? b : c) ? d : e;\n"},
+		{"a ? (b ? c : d) : (e ?? f); (a ?? b) ? c : d", "a ? b ? c : d : e ?? f;\na ?? b ? c : d;\n"},
+		{"(a ? b : c) + 1", "(a ? b : c) + 1;\n"},
+		{
+			"match(x){1|-2.5 if y=>\"a\",_=>b?c:d}",
+			"match (x) {\n\t1 | -2.5 if y => \"a\",\n\t_ => b ? c : d,\n};\n",
+		},
+		{"let m = match (x) { }", "let m = match (x) {};\n"},
+		{"let f = fn(x,y){return x+y;};", "let f = fn(x, y) {\n\treturn x + y;\n};\n"},
+		{"fn() {}", "fn() {};\n"},
+		{
+			"if (a) { b } else { if (c) { d } }",
+			"if (a) {\n\tb;\n} else {\n\tif (c) {\n\t\td;\n\t};\n};\n",
+		},
+		{
+			"let a = 1;\n\n\n\nlet b = 2;\nlet c = 3;",
+			"let a = 1;\n\nlet b = 2;\nlet c = 3;\n",
+		},
+	}
+	for _, tt := range tests {
+		res, err := Source([]byte(tt.input))
+		if err != nil {
+			t.Errorf("Source(%q) returned error: %s", tt.input, err)
+			continue
+		}
+		if string(res) != tt.expected {
+			t.Errorf("Source(%q) wrong.\nexpected=%q\ngot=%q", tt.input, tt.expected, res)
+		}
+	}
+}
+
+func TestSourceComments(t *testing.T) {
+	input := `// header
+
+let f = fn(x) { // doubles x
+	x * 2 // result
+	// end of body
+};
+// footer`
+	expected := `// header
+
+let f = fn(x) {
+	// doubles x
+	x * 2; // result
+	// end of body
+};
+// footer
+`
+	res, err := Source([]byte(input))
+	if err != nil {
+		t.Fatalf("Source returned error: %s", err)
+	}
+	if string(res) != expected {
+		t.Errorf("wrong output.\nexpected=%q\ngot=%q", expected, res)
+	}
+}
+
+// expressions printed on one line keep the comments of their lines
+// after the statement
+func TestSourceCommentsInExpressions(t *testing.T) {
+	input := `let h = {
+	"a": 1, // first
+	"b": [
+		2 // second
+	]
+};
+echo(
+	1
+); // call
+let a = 1; let b = 2; // of b
+let m = match (a) { 1 => 2 }; // one
+match (a) {
+	// one
+	1 => f(
+		2 // two
+	),
+	_ => 3 // three
+}; // end`
+	expected := `let h = {"a": 1, "b": [2]}; // first // second
+echo(1); // call
+let a = 1;
+let b = 2; // of b
+let m = match (a) {
+	1 => 2,
+}; // one
+match (a) {
+	// one
+	1 => f(2), // two
+	_ => 3, // three
+}; // end
+`
+	res, err := Source([]byte(input))
+	if err != nil {
+		t.Fatalf("Source returned error: %s", err)
+	}
+	if string(res) != expected {
+		t.Errorf("wrong output.\nexpected=%q\ngot=%q", expected, res)
+	}
+	again, _ := Source(res)
+	if string(again) != expected {
+		t.Errorf("formatting is not idempotent.\nsecond=%q", again)
+	}
+}
+
+func TestSourceParseError(t *testing.T) {
+	input := "let = 5;"
+	res, err := Source([]byte(input))
+	if err == nil {
+		t.Fatalf("expected parse error")
+	}
+	if string(res) != input {
+		t.Errorf("source changed on error. got=%q", res)
+	}
+}
+
+var roundTripInputs = []string{
+	`let five = 5; let ten = 10;
+let add = fn(x, y) { x + y; };
+let result = add(five, ten);
+!-five / 5 * (1 - 2);
+5 < 10 > 5;`,
+	`if (5 < 10) { return true; } else { return false; }
+10 == 10; 10 != 9;
+"foo bar"`,
+	`let newAdder = fn(x) { fn(y) { x + y }; }; // closure
+let addTwo = newAdder(2);
+echo(addTwo(2) - (3 - 4) - -(5 * (6 + 7)));`,
+	`let lib = import "path/to/lib";
+export let greet = fn(name) { lib.hello(name) + lib["suffix"] };
+fn(f) { f(f) }(fn(g) { 1 })`,
+	`let xs = [1, 2.5, "three", [4]];
+xs[1:-1][0] + xs[:2][xs[3][0] - 4];
+let config = {"name": xs[2], "size": {"w": 1, "h": 2}};`,
+	`let sign = fn(n) { n < 0 ? -1 : n == 0 ? 0 : 1 };
+let name = fn(n) { match (sign(n)) { -1 => "negative", 0 if n == 0.0 => "zero", _ => "positive", } };
+echo(name(3) ?? "none" ? "ok" : "no");`,
+	`let m = match (x) { 1 => 2 }; // one
+let y = 1;
+match (y) {
+	1 => "one", // ends the arms
+}; // ends the match
+echo(m);`,
+}
+
+func TestSourceIdempotent(t *testing.T) {
+	for _, input := range roundTripInputs {
+		first, err := Source([]byte(input))
+		if err != nil {
+			t.Fatalf("Source(%q) returned error: %s", input, err)
+		}
+		second, err := Source(first)
+		if err != nil {
+			t.Fatalf("Source(%q) returned error: %s", first, err)
+		}
+		if string(first) != string(second) {
+			t.Errorf("formatting is not idempotent.\nfirst=%q\nsecond=%q", first, second)
+		}
+	}
+}
+
+// the fully parenthesised String() output only matches if both
+// sources parse to the same tree
+func TestSourceReparses(t *testing.T) {
+	for _, input := range roundTripInputs {
+		res, err := Source([]byte(input))
+		if err != nil {
+			t.Fatalf("Source(%q) returned error: %s", input, err)
+		}
+		original := parser.New(lexer.New(input)).ParseProgram().String()
+		formatted := parser.New(lexer.New(string(res))).ParseProgram().String()
+		if original != formatted {
+			t.Errorf("formatted source parses differently.\noriginal=%q\nformatted=%q", original, formatted)
+		}
+	}
+}
+
+func TestNode(t *testing.T) {
+	program := parser.New(lexer.New("fn(x) { x * (2 + 1) }")).ParseProgram()
+	expected := "fn(x) {\n\tx * (2 + 1);\n}"
+	if got := Node(program.Statements[0]); got != expected+";" {
+		t.Errorf("wrong statement output. expected=%q, got=%q", expected+";", got)
+	}
+}
@@ -0,0 +1,63 @@
+// Package framing reads and writes messages preceded by a Content-Length
+// header, the base protocol of both LSP and the Debug Adapter Protocol.
+package framing
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"sync"
+)
+
+// MAX_CONTENT_LENGTH bounds the size of the messages a Reader accepts.
+const MAX_CONTENT_LENGTH = 1 << 26
+
+type Reader struct {
+	r *bufio.Reader
+}
+
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Read returns the body of the next message.
+func (r *Reader) Read() ([]byte, error) {
+	header, err := textproto.NewReader(r.r).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid Content-Length %q", header.Get("Content-Length"))
+	}
+	if length > MAX_CONTENT_LENGTH {
+		return nil, fmt.Errorf("Content-Length %d exceeds the maximum of %d bytes", length, MAX_CONTENT_LENGTH)
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r.r, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// Writer writes messages; it is safe for concurrent use.
+type Writer struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+func (w *Writer) Write(body []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := fmt.Fprintf(w.w, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+	_, err := w.w.Write(body)
+	return err
+}
@@ -1,6 +1,10 @@
 package lexer
 
-import "interpreter/token"
+import (
+	"fmt"
+	"interpreter/token"
+	"strings"
+)
 
 //readPosition always points to the “next” character in the input.
 //position points to the character in the input that corresponds to the ch byte.
@@ -9,17 +13,36 @@ type Lexer struct {
 	position     int  //current position in input (points to current char)
 	readPosition int  //current reading position in input
 	ch           byte // current char under examination
+	line         int  //line of ch, starting at 1
+	column       int  //column of ch, starting at 1
+
+	comments []token.Token //comments skipped so far
+	prev     token.Token   //last token returned
+	errors   []Error       //malformed tokens returned so far
+}
+
+//Error describes what is wrong with a malformed token, which the lexer
+//returns as ILLEGAL.
+type Error struct {
+	Token   token.Token
+	Message string
 }
 
 func New(input string) *Lexer {
 	l := &Lexer{
 		input: input,
+		line:  1,
 	}
 	l.readChar()
 	return l
 }
 
 func (l *Lexer) readChar() {
+	if l.ch == '\n' {
+		l.line += 1
+		l.column = 0
+	}
+	l.column += 1
 	if l.readPosition >= len(l.input) || l.readPosition < 0 {
 		// sets l.ch to 0, which is the ASCII code for the "NUL" character
 		l.ch = 0
@@ -41,9 +64,16 @@ func (l *Lexer) peekChar() byte {
 }
 
 func (l *Lexer) NextToken() token.Token {
+	t := l.nextToken()
+	l.prev = t
+	return t
+}
+
+func (l *Lexer) nextToken() token.Token {
 	var t token.Token
 	//skip the white space
 	l.skipWhitespace()
+	line, column := l.line, l.column
 
 	switch l.ch {
 	case '=':
@@ -51,17 +81,24 @@ func (l *Lexer) NextToken() token.Token {
 			c := l.ch
 			l.readChar()
 			t = token.Token{Type: token.EQ, Literal: string(c) + string(l.ch)}
+		} else if l.peekChar() == '>' {
+			l.readChar()
+			t = token.Token{Type: token.ARROW, Literal: "=>"}
 		} else {
 			t = newToken(token.ASSIGN, l.ch)
 		}
 	case ';':
 		t = newToken(token.SEMICOLON, l.ch)
+	case ':':
+		t = newToken(token.COLON, l.ch)
 	case '(':
 		t = newToken(token.LPAREN, l.ch)
 	case ')':
 		t = newToken(token.RPAREN, l.ch)
 	case ',':
 		t = newToken(token.COMMA, l.ch)
+	case '.':
+		t = newToken(token.DOT, l.ch)
 	case '!':
 		if l.peekChar() == '=' {
 			c := l.ch
@@ -70,22 +107,70 @@ func (l *Lexer) NextToken() token.Token {
 		} else {
 			t = newToken(token.BANG, l.ch)
 		}
+	case '?':
+		switch l.peekChar() {
+		case '?':
+			l.readChar()
+			t = token.Token{Type: token.NULLISH, Literal: "??"}
+		case '.':
+			l.readChar()
+			t = token.Token{Type: token.QUESTION_DOT, Literal: "?."}
+		default:
+			t = newToken(token.QUESTION, l.ch)
+		}
 	case '+':
 		t = newToken(token.PLUS, l.ch)
 	case '-':
 		t = newToken(token.MINUS, l.ch)
 	case '*':
-		t = newToken(token.ASTERISK, l.ch)
+		if l.peekChar() == '*' {
+			l.readChar()
+			t = token.Token{Type: token.POWER, Literal: "**"}
+		} else {
+			t = newToken(token.ASTERISK, l.ch)
+		}
+	case '%':
+		t = newToken(token.PERCENT, l.ch)
+	case '&':
+		t = newToken(token.AMPERSAND, l.ch)
+	case '|':
+		t = newToken(token.PIPE, l.ch)
+	case '^':
+		t = newToken(token.CARET, l.ch)
+	case '~':
+		t = newToken(token.TILDE, l.ch)
 	case '/':
-		t = newToken(token.SLASH, l.ch)
+		regex := ""
+		if l.regexAllowed() {
+			regex = l.readRegex()
+		}
+		if regex != "" {
+			t = token.Token{Type: token.REGEX, Literal: regex}
+		} else {
+			t = newToken(token.SLASH, l.ch)
+		}
 	case '<':
-		t = newToken(token.LT, l.ch)
+		if l.peekChar() == '<' {
+			l.readChar()
+			t = token.Token{Type: token.SHL, Literal: "<<"}
+		} else {
+			t = newToken(token.LT, l.ch)
+		}
 	case '>':
-		t = newToken(token.GT, l.ch)
+		if l.peekChar() == '>' {
+			l.readChar()
+			t = token.Token{Type: token.SHR, Literal: ">>"}
+		} else {
+			t = newToken(token.GT, l.ch)
+		}
 	case '{':
 		t = newToken(token.LBRACE, l.ch)
 	case '}':
 		t = newToken(token.RBRACE, l.ch)
+	case '[':
+		t = newToken(token.LBRACKET, l.ch)
+	case ']':
+		t = newToken(token.RBRACKET, l.ch)
 	case '"':
 		t.Type = token.STRING
 		t.Literal = l.readString()
@@ -96,11 +181,20 @@ func (l *Lexer) NextToken() token.Token {
 		if isLetter(l.ch) {
 			t.Literal = l.readIdentifier()
 			t.Type = token.CheckIdent(t.Literal)
+			//a name after '.' is a member, even one spelled like a keyword: re.match(s)
+			if l.prev.Type == token.DOT || l.prev.Type == token.QUESTION_DOT {
+				t.Type = token.IDENT
+			}
+			t.Line, t.Column = line, column
 			return t
 
 		} else if isDigit(l.ch) {
-			t.Literal = l.readNumber()
-			t.Type = token.INT
+			t.Literal, t.Type = l.readNumber()
+			t.Line, t.Column = line, column
+			if msg := numberError(t.Literal); msg != "" {
+				t.Type = token.ILLEGAL
+				l.errors = append(l.errors, Error{Token: t, Message: msg})
+			}
 			return t
 		} else {
 			t = newToken(token.ILLEGAL, l.ch)
@@ -108,28 +202,171 @@ func (l *Lexer) NextToken() token.Token {
 	}
 
 	l.readChar()
+	t.Line, t.Column = line, column
 	return t
 }
+
+//identifiers start with a letter, digits may follow
 func (l *Lexer) readIdentifier() string {
 	start := l.position
-	for isLetter(l.ch) {
+	for isLetter(l.ch) || isDigit(l.ch) {
 		l.readChar()
 	}
 	return l.input[start:l.position]
 }
 
-func (l *Lexer) readNumber() string {
+//reads an integer, or a float when a fraction follows decimal digits.
+//The letters and underscores that follow the digits are read with them,
+//so that numberError sees the whole of a malformed number.
+func (l *Lexer) readNumber() (string, token.TokenType) {
 	start := l.position
-	for isDigit(l.ch) {
+	for isDigit(l.ch) || isLetter(l.ch) {
 		l.readChar()
 	}
-	return l.input[start:l.position]
+	if _, _, ok := numberBase(l.input[start:l.position]); ok || l.ch != '.' || !isDigit(l.peekChar()) {
+		return l.input[start:l.position], token.INT
+	}
+	l.readChar()
+	for isDigit(l.ch) || isLetter(l.ch) {
+		l.readChar()
+	}
+	return l.input[start:l.position], token.FLOAT
+}
+
+//the prefixes of integers in other bases than 10
+var bases = map[string]struct {
+	base int
+	name string
+}{
+	"0x": {16, "hexadecimal"},
+	"0o": {8, "octal"},
+	"0b": {2, "binary"},
 }
 
+//the base of a number with a prefix
+func numberBase(lit string) (int, string, bool) {
+	if len(lit) < 2 {
+		return 0, "", false
+	}
+	prefix, ok := bases[strings.ToLower(lit[:2])]
+	return prefix.base, prefix.name, ok
+}
+
+//numberError describes what is wrong with a number literal, "" if it is
+//well-formed: its digits must be valid in its base, given by a 0x, 0o
+//or 0b prefix, or a leading 0 for octal, and single underscores may
+//separate them, or follow the prefix.
+func numberError(lit string) string {
+	base, name, digits := 10, "decimal", lit
+	if prefixBase, prefixName, ok := numberBase(lit); ok {
+		base, name, digits = prefixBase, prefixName, lit[2:]
+		if strings.Trim(digits, "_") == "" {
+			return fmt.Sprintf("malformed number %s: no digits after %s", lit, lit[:2])
+		}
+		digits = strings.TrimPrefix(digits, "_")
+	} else if len(lit) > 1 && lit[0] == '0' && !strings.Contains(lit, ".") {
+		base, name = 8, "octal"
+	}
+	for _, part := range strings.Split(digits, ".") {
+		for i := 0; i < len(part); i++ {
+			c := part[i]
+			if c == '_' {
+				if i == 0 || i+1 == len(part) || part[i+1] == '_' {
+					return fmt.Sprintf("malformed number %s: '_' must separate successive digits", lit)
+				}
+				continue
+			}
+			if digitValue(c) >= base {
+				return fmt.Sprintf("malformed number %s: invalid digit '%c' in %s literal", lit, c, name)
+			}
+		}
+	}
+	return ""
+}
+
+//the value of a digit in bases up to 16, 16 for anything else
+func digitValue(c byte) int {
+	switch {
+	case '0' <= c && c <= '9':
+		return int(c - '0')
+	case 'a' <= c && c <= 'f':
+		return int(c-'a') + 10
+	case 'A' <= c && c <= 'F':
+		return int(c-'A') + 10
+	}
+	return 16
+}
+
+//a '/' after an operand divides it, anywhere else it may start a regex
+func (l *Lexer) regexAllowed() bool {
+	switch l.prev.Type {
+	case token.IDENT, token.INT, token.FLOAT, token.STRING, token.REGEX, token.TRUE, token.FALSE,
+		token.NULL, token.RPAREN, token.RBRACKET, token.RBRACE:
+		return false
+	}
+	return true
+}
+
+//reads /pattern/flags, where neither \/ nor a '/' in a [class] ends
+//the pattern. It returns "" when the pattern does not end on its line.
+func (l *Lexer) readRegex() string {
+	inClass := false
+	for i := l.position + 1; i < len(l.input); i++ {
+		switch c := l.input[i]; {
+		case c == '\\' && i+1 < len(l.input) && l.input[i+1] != '\n':
+			i++
+		case c == '\n':
+			return ""
+		case c == '[':
+			inClass = true
+		case c == ']':
+			inClass = false
+		case c == '/' && !inClass:
+			for i+1 < len(l.input) && isLetter(l.input[i+1]) {
+				i++
+			}
+			literal := l.input[l.position : i+1]
+			for l.position < i {
+				l.readChar()
+			}
+			return literal
+		}
+	}
+	return ""
+}
+
+//skips white space and // comments, which are kept aside for Comments
 func (l *Lexer) skipWhitespace() {
-	for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r' {
+	for {
+		switch {
+		case l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r':
+			l.readChar()
+		case l.ch == '/' && l.peekChar() == '/':
+			l.readComment()
+		default:
+			return
+		}
+	}
+}
+
+func (l *Lexer) readComment() {
+	t := token.Token{Type: token.COMMENT, Line: l.line, Column: l.column}
+	start := l.position
+	for l.ch != '\n' && l.ch != 0 {
 		l.readChar()
 	}
+	t.Literal = l.input[start:l.position]
+	l.comments = append(l.comments, t)
+}
+
+//Errors returns the malformed tokens the lexer has returned so far.
+func (l *Lexer) Errors() []Error {
+	return l.errors
+}
+
+//Comments returns the comments the lexer has skipped so far, in source order.
+func (l *Lexer) Comments() []token.Token {
+	return l.comments
 }
 
 func (l *Lexer) readString() string {
@@ -157,3 +157,344 @@ if (5 < 10) {
 		}
 	}
 }
+
+func TestNextToken_modules(t *testing.T) {
+	input := `export let x = lib.a[0];
+import "lib";`
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.EXPORT, "export"},
+		{token.LET, "let"},
+		{token.IDENT, "x"},
+		{token.ASSIGN, "="},
+		{token.IDENT, "lib"},
+		{token.DOT, "."},
+		{token.IDENT, "a"},
+		{token.LBRACKET, "["},
+		{token.INT, "0"},
+		{token.RBRACKET, "]"},
+		{token.SEMICOLON, ";"},
+		{token.IMPORT, "import"},
+		{token.STRING, "lib"},
+		{token.SEMICOLON, ";"},
+		{token.EOF, ""},
+	}
+	lxr := New(input)
+
+	for i, tt := range tests {
+		tok := lxr.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q",
+				i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestNextToken_comments(t *testing.T) {
+	input := `// leading
+let x = 10 / 2; // trailing
+x`
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedLine    int
+		expectedColumn  int
+	}{
+		{token.LET, "let", 2, 1},
+		{token.IDENT, "x", 2, 5},
+		{token.ASSIGN, "=", 2, 7},
+		{token.INT, "10", 2, 9},
+		{token.SLASH, "/", 2, 12},
+		{token.INT, "2", 2, 14},
+		{token.SEMICOLON, ";", 2, 15},
+		{token.IDENT, "x", 3, 1},
+		{token.EOF, "", 3, 2},
+	}
+	lxr := New(input)
+
+	for i, tt := range tests {
+		tok := lxr.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q",
+				i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok.Line != tt.expectedLine || tok.Column != tt.expectedColumn {
+			t.Fatalf("tests[%d] - position wrong. expected=%d:%d, got=%d:%d",
+				i, tt.expectedLine, tt.expectedColumn, tok.Line, tok.Column)
+		}
+	}
+
+	comments := lxr.Comments()
+	if len(comments) != 2 {
+		t.Fatalf("wrong number of comments. got=%d", len(comments))
+	}
+	if comments[0].Literal != "// leading" || comments[0].Line != 1 {
+		t.Errorf("wrong first comment. got=%+v", comments[0])
+	}
+	if comments[1].Literal != "// trailing" || comments[1].Line != 2 || comments[1].Column != 17 {
+		t.Errorf("wrong second comment. got=%+v", comments[1])
+	}
+}
+
+func TestNextToken_floats(t *testing.T) {
+	input := `1.5 10.25 3. lib.x2 4.e`
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.FLOAT, "1.5"},
+		{token.FLOAT, "10.25"},
+		{token.INT, "3"},
+		{token.DOT, "."},
+		{token.IDENT, "lib"},
+		{token.DOT, "."},
+		{token.IDENT, "x2"},
+		{token.INT, "4"},
+		{token.DOT, "."},
+		{token.IDENT, "e"},
+		{token.EOF, ""},
+	}
+	lxr := New(input)
+
+	for i, tt := range tests {
+		tok := lxr.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q",
+				i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestNextToken_regex(t *testing.T) {
+	input := `/a\/b[/]/i.match(x / 2, (y) / /c/)
+let r = /open
+1 / 2`
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.REGEX, `/a\/b[/]/i`},
+		{token.DOT, "."},
+		{token.IDENT, "match"},
+		{token.LPAREN, "("},
+		{token.IDENT, "x"},
+		{token.SLASH, "/"},
+		{token.INT, "2"},
+		{token.COMMA, ","},
+		{token.LPAREN, "("},
+		{token.IDENT, "y"},
+		{token.RPAREN, ")"},
+		{token.SLASH, "/"},
+		{token.REGEX, "/c/"},
+		{token.RPAREN, ")"},
+		{token.LET, "let"},
+		{token.IDENT, "r"},
+		{token.ASSIGN, "="},
+		{token.SLASH, "/"},
+		{token.IDENT, "open"},
+		{token.INT, "1"},
+		{token.SLASH, "/"},
+		{token.INT, "2"},
+		{token.EOF, ""},
+	}
+	lxr := New(input)
+
+	for i, tt := range tests {
+		tok := lxr.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q",
+				i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+	lxr = New("/a/\nb")
+	lxr.NextToken()
+	if tok := lxr.NextToken(); tok.Line != 2 || tok.Column != 1 {
+		t.Errorf("wrong position after regex. got=%d:%d", tok.Line, tok.Column)
+	}
+}
+
+func TestNextToken_null(t *testing.T) {
+	input := `a ?? null; f?.(1)?.[x]?.y; c ? d`
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "a"},
+		{token.NULLISH, "??"},
+		{token.NULL, "null"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "f"},
+		{token.QUESTION_DOT, "?."},
+		{token.LPAREN, "("},
+		{token.INT, "1"},
+		{token.RPAREN, ")"},
+		{token.QUESTION_DOT, "?."},
+		{token.LBRACKET, "["},
+		{token.IDENT, "x"},
+		{token.RBRACKET, "]"},
+		{token.QUESTION_DOT, "?."},
+		{token.IDENT, "y"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "c"},
+		{token.QUESTION, "?"},
+		{token.IDENT, "d"},
+		{token.EOF, ""},
+	}
+	lxr := New(input)
+
+	for i, tt := range tests {
+		tok := lxr.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - wrong token. expected=%q %q, got=%q %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestNextToken_operators(t *testing.T) {
+	input := `a % b ** c * d & e | f ^ ~g << h >> i < j > k`
+	expected := []token.TokenType{
+		token.IDENT, token.PERCENT, token.IDENT, token.POWER, token.IDENT, token.ASTERISK,
+		token.IDENT, token.AMPERSAND, token.IDENT, token.PIPE, token.IDENT, token.CARET,
+		token.TILDE, token.IDENT, token.SHL, token.IDENT, token.SHR, token.IDENT,
+		token.LT, token.IDENT, token.GT, token.IDENT, token.EOF,
+	}
+	lxr := New(input)
+
+	for i, typ := range expected {
+		if tok := lxr.NextToken(); tok.Type != typ {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, typ, tok.Type)
+		}
+	}
+}
+
+func TestNextToken_numbers(t *testing.T) {
+	input := `0xFF 0X1f 0o17 0b1010 0x_ff 1_000_000 3_000.5 017 0x1.5`
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.INT, "0xFF"},
+		{token.INT, "0X1f"},
+		{token.INT, "0o17"},
+		{token.INT, "0b1010"},
+		{token.INT, "0x_ff"},
+		{token.INT, "1_000_000"},
+		{token.FLOAT, "3_000.5"},
+		{token.INT, "017"},
+		{token.INT, "0x1"},
+		{token.DOT, "."},
+		{token.INT, "5"},
+		{token.EOF, ""},
+	}
+	lxr := New(input)
+
+	for i, tt := range tests {
+		tok := lxr.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - wrong token. expected=%q %q, got=%q %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+	if errs := lxr.Errors(); len(errs) != 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+}
+
+func TestNextToken_malformedNumbers(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"0x", "malformed number 0x: no digits after 0x"},
+		{"0b_", "malformed number 0b_: no digits after 0b"},
+		{"1__0", "malformed number 1__0: '_' must separate successive digits"},
+		{"1_", "malformed number 1_: '_' must separate successive digits"},
+		{"1_.5", "malformed number 1_.5: '_' must separate successive digits"},
+		{"0x__1", "malformed number 0x__1: '_' must separate successive digits"},
+		{"0b102", "malformed number 0b102: invalid digit '2' in binary literal"},
+		{"0o8", "malformed number 0o8: invalid digit '8' in octal literal"},
+		{"09", "malformed number 09: invalid digit '9' in octal literal"},
+		{"0xfg", "malformed number 0xfg: invalid digit 'g' in hexadecimal literal"},
+		{"12ab", "malformed number 12ab: invalid digit 'a' in decimal literal"},
+	}
+	for _, tt := range tests {
+		lxr := New("x = " + tt.input + ";")
+		lxr.NextToken()
+		lxr.NextToken()
+		tok := lxr.NextToken()
+		if tok.Type != token.ILLEGAL || tok.Literal != tt.input {
+			t.Errorf("%s: wrong token. got=%q %q", tt.input, tok.Type, tok.Literal)
+		}
+		if next := lxr.NextToken(); next.Type != token.SEMICOLON {
+			t.Errorf("%s: number not read whole, next token %q", tt.input, next.Literal)
+		}
+		errs := lxr.Errors()
+		if len(errs) != 1 || errs[0].Message != tt.expected || errs[0].Token != tok {
+			t.Errorf("%s: wrong errors. expected=%q, got=%v", tt.input, tt.expected, errs)
+		}
+	}
+}
+
+func TestNextToken_conditional(t *testing.T) {
+	input := `c ? a : b; match (x) { 1 | 2 if y => z, _ => w } r?.match`
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "c"},
+		{token.QUESTION, "?"},
+		{token.IDENT, "a"},
+		{token.COLON, ":"},
+		{token.IDENT, "b"},
+		{token.SEMICOLON, ";"},
+		{token.MATCH, "match"},
+		{token.LPAREN, "("},
+		{token.IDENT, "x"},
+		{token.RPAREN, ")"},
+		{token.LBRACE, "{"},
+		{token.INT, "1"},
+		{token.PIPE, "|"},
+		{token.INT, "2"},
+		{token.IF, "if"},
+		{token.IDENT, "y"},
+		{token.ARROW, "=>"},
+		{token.IDENT, "z"},
+		{token.COMMA, ","},
+		{token.IDENT, "_"},
+		{token.ARROW, "=>"},
+		{token.IDENT, "w"},
+		{token.RBRACE, "}"},
+		{token.IDENT, "r"},
+		{token.QUESTION_DOT, "?."},
+		{token.IDENT, "match"},
+		{token.EOF, ""},
+	}
+	lxr := New(input)
+
+	for i, tt := range tests {
+		tok := lxr.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - wrong token. expected=%q %q, got=%q %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
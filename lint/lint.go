@@ -0,0 +1,225 @@
+// Package lint reports likely mistakes in programs without running them.
+package lint
+
+import (
+	"fmt"
+	"interpreter/ast"
+	"interpreter/evaluator"
+	"interpreter/lexer"
+	"interpreter/parser"
+	"interpreter/token"
+	"sort"
+	"strings"
+)
+
+// Rule IDs, used in findings and to disable rules.
+const (
+	UnusedVariable    = "unused-variable"
+	UnusedParameter   = "unused-parameter"
+	Shadow            = "shadow"
+	Undefined         = "undefined"
+	BuiltinArity      = "builtin-arity"
+	Unreachable       = "unreachable"
+	ConstantCondition = "constant-condition"
+	TypeMismatch      = "type-mismatch"
+)
+
+// Rules lists every rule ID.
+var Rules = []string{
+	UnusedVariable,
+	UnusedParameter,
+	Shadow,
+	Undefined,
+	BuiltinArity,
+	Unreachable,
+	ConstantCondition,
+	TypeMismatch,
+}
+
+type Finding struct {
+	Rule    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%d:%d: %s (%s)", f.Line, f.Column, f.Message, f.Rule)
+}
+
+type Config struct {
+	// Disabled holds the IDs of the rules not to report.
+	Disabled map[string]bool
+}
+
+// Source parses src and checks it. Source that does not parse is not
+// checked; the parser errors are returned instead.
+func Source(src []byte, config Config) ([]Finding, error) {
+	p := parser.New(lexer.New(string(src)))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return nil, fmt.Errorf("%s", strings.Join(p.Errors(), "\n"))
+	}
+	return Check(program, config), nil
+}
+
+// Check reports the findings of all enabled rules, sorted by position.
+func Check(program *ast.Program, config Config) []Finding {
+	c := &checker{config: config}
+	c.checkScopes(program)
+	c.checkNodes(program)
+	sort.SliceStable(c.findings, func(i, j int) bool {
+		a, b := c.findings[i], c.findings[j]
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return c.findings
+}
+
+type checker struct {
+	config   Config
+	findings []Finding
+}
+
+func (c *checker) report(rule string, tok token.Token, format string, a ...interface{}) {
+	if c.config.Disabled[rule] {
+		return
+	}
+	c.findings = append(c.findings, Finding{
+		Rule:    rule,
+		Line:    tok.Line,
+		Column:  tok.Column,
+		Message: fmt.Sprintf(format, a...),
+	})
+}
+
+// checkNodes runs the rules that need no scope information.
+func (c *checker) checkNodes(program *ast.Program) {
+	ast.Inspect(program, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.Program:
+			c.checkUnreachable(n.Statements)
+		case *ast.BlockStatement:
+			c.checkUnreachable(n.Statements)
+		case *ast.IfExpression:
+			c.checkCondition(n.Token, n.Condition)
+		case *ast.ConditionalExpression:
+			c.checkCondition(n.Token, n.Condition)
+		case *ast.MatchExpression:
+			c.checkArms(n)
+		case *ast.InfixExpression:
+			c.checkOperands(n)
+		}
+		return true
+	})
+}
+
+func (c *checker) checkUnreachable(list []ast.Statement) {
+	for i, stmt := range list {
+		if _, ok := stmt.(*ast.ReturnStatement); ok && i+1 < len(list) {
+			next := list[i+1]
+			c.report(Unreachable, firstToken(next), "unreachable statement after return")
+			return
+		}
+	}
+}
+
+func (c *checker) checkCondition(tok token.Token, condition ast.Expression) {
+	if value, ok := constantTruth(condition); ok {
+		c.report(ConstantCondition, tok, "condition is always %t", value)
+	}
+}
+
+// checkArms reports the arms after a default arm _ without a guard,
+// which matches every value.
+func (c *checker) checkArms(me *ast.MatchExpression) {
+	for i, arm := range me.Arms {
+		if len(arm.Patterns) == 0 && arm.Guard == nil && i+1 < len(me.Arms) {
+			c.report(Unreachable, me.Arms[i+1].Token, "unreachable match arm after _")
+			return
+		}
+	}
+}
+
+// constantTruth reports the truthiness of conditions made only of literals.
+func constantTruth(exp ast.Expression) (bool, bool) {
+	switch exp := exp.(type) {
+	case *ast.Boolean:
+		return exp.Value, true
+	case *ast.NullLiteral:
+		return false, true
+	case *ast.IntegerLiteral, *ast.FloatLiteral, *ast.StringLiteral, *ast.RegexLiteral, *ast.FunctionLiteral, *ast.ArrayLiteral, *ast.HashLiteral:
+		return true, true
+	case *ast.PrefixExpression:
+		if exp.Operator == "!" {
+			if value, ok := constantTruth(exp.Right); ok {
+				return !value, true
+			}
+		}
+	}
+	return false, false
+}
+
+func (c *checker) checkOperands(ie *ast.InfixExpression) {
+	if ie.Operator == "==" || ie.Operator == "!=" || ie.Operator == "??" {
+		return
+	}
+	left, right := literalType(ie.Left), literalType(ie.Right)
+	if left != "" && right != "" && left != right && !(isNumeric(left) && isNumeric(right)) {
+		c.report(TypeMismatch, ie.Token, "mismatched types: %s %s %s", left, ie.Operator, right)
+	}
+}
+
+// literalType returns the type name of literal values, "" for anything else.
+func literalType(exp ast.Expression) string {
+	switch exp := exp.(type) {
+	case *ast.IntegerLiteral:
+		return "INTEGER"
+	case *ast.FloatLiteral:
+		return "FLOAT"
+	case *ast.StringLiteral:
+		return "STRING"
+	case *ast.RegexLiteral:
+		return "REGEX"
+	case *ast.Boolean:
+		return "BOOLEAN"
+	case *ast.NullLiteral:
+		return "NULL"
+	case *ast.FunctionLiteral:
+		return "FUNCTION"
+	case *ast.ArrayLiteral:
+		return "ARRAY"
+	case *ast.HashLiteral:
+		return "HASH"
+	case *ast.PrefixExpression:
+		if exp.Operator == "!" {
+			return "BOOLEAN"
+		}
+		if right := literalType(exp.Right); isNumeric(right) {
+			return right
+		}
+	}
+	return ""
+}
+
+// integers and floats mix in arithmetic
+func isNumeric(typ string) bool {
+	return typ == "INTEGER" || typ == "FLOAT"
+}
+
+// firstToken returns the token a statement starts with.
+func firstToken(stmt ast.Statement) token.Token {
+	first, _ := ast.Span(stmt)
+	return first
+}
+
+// builtinArity describes how many arguments a builtin accepts.
+func builtinArity(name string) (int, int, bool) {
+	builtin, ok := evaluator.GetBuiltin(name)
+	if !ok {
+		return 0, 0, false
+	}
+	return builtin.MinArgs, builtin.MaxArgs, true
+}
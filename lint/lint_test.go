@@ -0,0 +1,153 @@
+package lint
+
+import (
+	"testing"
+)
+
+func TestRules(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{
+			"let a = 1; echo(a);",
+			nil,
+		},
+		{
+			"let a = 1;",
+			[]string{"1:5: a is declared but never used (unused-variable)"},
+		},
+		{
+			"export let a = 1; let _b = 2;",
+			nil,
+		},
+		{
+			"let f = fn(x, y) { x }; f(1, 2);",
+			[]string{"1:15: parameter y is never used (unused-parameter)"},
+		},
+		{
+			"let f = fn() { f() }; f();",
+			nil,
+		},
+		{
+			"let x = 1; let f = fn(x) { x }; f(x);",
+			[]string{"1:23: x shadows a binding of an enclosing function (shadow)"},
+		},
+		{
+			"let len = fn(s) { s }; len(1);",
+			[]string{"1:5: len shadows the builtin len (shadow)"},
+		},
+		{
+			"let x = 1; let i = 0; let j = 2; echo([x], x[i:j]);",
+			nil,
+		},
+		{
+			"let a = [1, foo()]; a[bar():baz()];",
+			[]string{
+				"1:13: call of undefined function foo (undefined)",
+				"1:23: call of undefined function bar (undefined)",
+				"1:29: call of undefined function baz (undefined)",
+			},
+		},
+		{
+			`let y = 2; let k = "k"; echo({"k": y, k: 1});`,
+			nil,
+		},
+		{
+			`let h = {foo(): 1, "k": bar()}; h;`,
+			[]string{
+				"1:10: call of undefined function foo (undefined)",
+				"1:25: call of undefined function bar (undefined)",
+			},
+		},
+		{
+			"foo(1);",
+			[]string{"1:1: call of undefined function foo (undefined)"},
+		},
+		{
+			"foo(); let foo = fn() { 1 }; foo();",
+			[]string{"1:1: call of undefined function foo (undefined)"},
+		},
+		{
+			`len("a", "b"); echo(); len("ok");`,
+			[]string{"1:1: len takes 1 arguments, got 2 (builtin-arity)"},
+		},
+		{
+			"let f = fn() { return 1; echo(2); }; f();",
+			[]string{"1:26: unreachable statement after return (unreachable)"},
+		},
+		{
+			"if (true) { 1 }; if (!1) { 2 }; let a = 1; if (a) { 3 };",
+			[]string{
+				"1:1: condition is always true (constant-condition)",
+				"1:18: condition is always false (constant-condition)",
+			},
+		},
+		{
+			`1 + "a"; -1 * true; "a" == 1; 1 + 2; 1 * -2.5; "b" - 0.5;`,
+			[]string{
+				"1:3: mismatched types: INTEGER + STRING (type-mismatch)",
+				"1:13: mismatched types: INTEGER * BOOLEAN (type-mismatch)",
+				"1:52: mismatched types: STRING - FLOAT (type-mismatch)",
+			},
+		},
+		{
+			`let a = 1; let b = 2; let c = 3; echo(a ? b : c, true ? 1 : 2);`,
+			[]string{"1:55: condition is always true (constant-condition)"},
+		},
+		{
+			`let v = 1; let w = 2; echo(match (v) { 1 if w => 2, _ => 3, 4 => 5 });`,
+			[]string{"1:61: unreachable match arm after _ (unreachable)"},
+		},
+		{
+			`let m = import "m"; let name = 1; echo(m.name);`,
+			[]string{"1:25: name is declared but never used (unused-variable)"},
+		},
+		{
+			`let n = 1; let k = 2; echo(match (n) { 1 if k > 0 => n > 0 ? n : k, _ => 0 });`,
+			nil,
+		},
+		{
+			`if (null) { 1 }; null ?? "a"; null + 1;`,
+			[]string{
+				"1:1: condition is always false (constant-condition)",
+				"1:36: mismatched types: NULL + INTEGER (type-mismatch)",
+			},
+		},
+	}
+	for _, tt := range tests {
+		findings, err := Source([]byte(tt.input), Config{})
+		if err != nil {
+			t.Fatalf("Source(%q) returned error: %s", tt.input, err)
+		}
+		if len(findings) != len(tt.expected) {
+			t.Errorf("wrong number of findings for %q. expected=%d, got=%v",
+				tt.input, len(tt.expected), findings)
+			continue
+		}
+		for i, f := range findings {
+			if f.String() != tt.expected[i] {
+				t.Errorf("wrong finding for %q. expected=%q, got=%q",
+					tt.input, tt.expected[i], f.String())
+			}
+		}
+	}
+}
+
+func TestDisabledRules(t *testing.T) {
+	input := `let a = 1; foo(); if (false) { 1 };`
+	config := Config{Disabled: map[string]bool{UnusedVariable: true, Undefined: true}}
+	findings, err := Source([]byte(input), config)
+	if err != nil {
+		t.Fatalf("Source returned error: %s", err)
+	}
+	if len(findings) != 1 || findings[0].Rule != ConstantCondition {
+		t.Errorf("wrong findings. got=%v", findings)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	if _, err := Source([]byte("let = 1;"), Config{}); err == nil {
+		t.Errorf("expected parse error")
+	}
+}
@@ -0,0 +1,152 @@
+package lint
+
+import "interpreter/ast"
+
+type binding struct {
+	ident     *ast.Identifier
+	parameter bool
+	exported  bool
+	used      bool
+}
+
+// scope holds the bindings of a program or function body. Blocks of
+// if expressions share the scope of the function they are in, as they
+// share its environment when evaluated.
+type scope struct {
+	outer    *scope
+	bindings map[string]*binding
+	order    []*binding
+	// function literals are checked once the scope has declared all of
+	// its bindings, since their bodies run after they are defined
+	functions []*ast.FunctionLiteral
+}
+
+func newScope(outer *scope) *scope {
+	return &scope{outer: outer, bindings: make(map[string]*binding)}
+}
+
+func (s *scope) lookup(name string) (*binding, bool) {
+	for ; s != nil; s = s.outer {
+		if b, ok := s.bindings[name]; ok {
+			return b, true
+		}
+	}
+	return nil, false
+}
+
+func (c *checker) checkScopes(program *ast.Program) {
+	s := newScope(nil)
+	c.inspect(s, program, nil)
+	c.closeScope(s)
+}
+
+func (c *checker) declare(s *scope, ident *ast.Identifier, parameter, exported bool) {
+	if ident == nil {
+		return
+	}
+	if s.outer != nil {
+		if _, ok := s.outer.lookup(ident.Value); ok {
+			c.report(Shadow, ident.Token, "%s shadows a binding of an enclosing function", ident.Value)
+		}
+	}
+	if _, _, ok := builtinArity(ident.Value); ok {
+		c.report(Shadow, ident.Token, "%s shadows the builtin %s", ident.Value, ident.Value)
+	}
+	b := &binding{ident: ident, parameter: parameter, exported: exported}
+	s.bindings[ident.Value] = b
+	s.order = append(s.order, b)
+}
+
+// closeScope checks the function bodies deferred in s, then reports
+// the bindings nothing refers to.
+func (c *checker) closeScope(s *scope) {
+	for i := 0; i < len(s.functions); i++ {
+		fl := s.functions[i]
+		fs := newScope(s)
+		for _, param := range fl.Parameters {
+			c.declare(fs, param, true, false)
+		}
+		if fl.Body != nil {
+			c.inspect(fs, fl.Body, nil)
+		}
+		c.closeScope(fs)
+	}
+
+	for _, b := range s.order {
+		if b.used || b.exported || b.ident.Value[0] == '_' {
+			continue
+		}
+		if b.parameter {
+			c.report(UnusedParameter, b.ident.Token, "parameter %s is never used", b.ident.Value)
+		} else {
+			c.report(UnusedVariable, b.ident.Token, "%s is declared but never used", b.ident.Value)
+		}
+	}
+}
+
+// inspect visits node and everything below it in scope s, except the
+// identifier skip, which names a binding rather than using one.
+func (c *checker) inspect(s *scope, node ast.Node, skip *ast.Identifier) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.Identifier:
+			if b, ok := s.lookup(n.Value); n != skip && ok {
+				b.used = true
+			}
+		case *ast.LetStatement:
+			if n != node {
+				c.let(s, n, false)
+				return false
+			}
+		case *ast.ExportStatement:
+			if n.Statement != nil {
+				c.let(s, n.Statement, true)
+			}
+			return false
+		case *ast.FunctionLiteral:
+			s.functions = append(s.functions, n)
+			return false
+		case *ast.CallExpression:
+			c.call(s, n)
+		case *ast.MemberExpression:
+			if n != node {
+				c.inspect(s, n, n.Property)
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// let declares the name of a let statement once its value is checked,
+// so that the value cannot refer to it.
+func (c *checker) let(s *scope, ls *ast.LetStatement, exported bool) {
+	c.inspect(s, ls, ls.Name)
+	c.declare(s, ls.Name, false, exported)
+}
+
+// call checks calls of names that are neither bound nor builtins, and
+// calls of builtins with a number of arguments they do not accept.
+func (c *checker) call(s *scope, ce *ast.CallExpression) {
+	ident, ok := ce.Function.(*ast.Identifier)
+	if !ok {
+		return
+	}
+	if _, ok := s.lookup(ident.Value); ok {
+		return
+	}
+	lo, hi, ok := builtinArity(ident.Value)
+	if !ok {
+		c.report(Undefined, ident.Token, "call of undefined function %s", ident.Value)
+		return
+	}
+	got := len(ce.Arguments)
+	switch {
+	case hi < 0 && got < lo:
+		c.report(BuiltinArity, ident.Token, "%s takes at least %d arguments, got %d", ident.Value, lo, got)
+	case hi >= 0 && (got < lo || got > hi) && lo == hi:
+		c.report(BuiltinArity, ident.Token, "%s takes %d arguments, got %d", ident.Value, lo, got)
+	case hi >= 0 && (got < lo || got > hi):
+		c.report(BuiltinArity, ident.Token, "%s takes %d to %d arguments, got %d", ident.Value, lo, hi, got)
+	}
+}
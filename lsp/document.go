@@ -0,0 +1,267 @@
+package lsp
+
+import (
+	"interpreter/ast"
+	"interpreter/lexer"
+	"interpreter/parser"
+	"interpreter/token"
+	"strings"
+	"unicode/utf8"
+)
+
+// document is an open text document and the result of parsing it.
+type document struct {
+	uri     string
+	text    string
+	lines   []string
+	program *ast.Program
+	errors  []parser.Error
+}
+
+func newDocument(uri, text string) *document {
+	p := parser.New(lexer.New(text))
+	return &document{
+		uri:     uri,
+		text:    text,
+		lines:   strings.Split(text, "\n"),
+		program: p.ParseProgram(),
+		errors:  p.ErrorList(),
+	}
+}
+
+// position converts a 1-based line and byte column to an LSP position,
+// whose character offset counts UTF-16 code units.
+func (d *document) position(line, column int) Position {
+	if line < 1 || line > len(d.lines) {
+		return Position{Line: line - 1, Character: column - 1}
+	}
+	text := d.lines[line-1]
+	if column-1 < len(text) {
+		text = text[:column-1]
+	}
+	return Position{Line: line - 1, Character: utf16Len(text)}
+}
+
+// column converts an LSP position to a 1-based line and byte column.
+func (d *document) column(pos Position) (int, int) {
+	if pos.Line < 0 || pos.Line >= len(d.lines) {
+		return pos.Line + 1, pos.Character + 1
+	}
+	text := d.lines[pos.Line]
+	units := 0
+	for i, r := range text {
+		if units >= pos.Character {
+			return pos.Line + 1, i + 1
+		}
+		units += utf16RuneLen(r)
+	}
+	return pos.Line + 1, len(text) + 1
+}
+
+// tokenRange is the range covered by a token.
+func (d *document) tokenRange(t token.Token) Range {
+	length := len(t.Literal)
+	if t.Type == token.STRING {
+		length += 2
+	}
+	return Range{
+		Start: d.position(t.Line, t.Column),
+		End:   d.position(t.Line, t.Column+length),
+	}
+}
+
+// nodeRange is the range from the first to the last token of node.
+func (d *document) nodeRange(node ast.Node) Range {
+	first, last := ast.Span(node)
+	return Range{Start: d.tokenRange(first).Start, End: d.tokenRange(last).End}
+}
+
+// fullRange covers the whole text.
+func (d *document) fullRange() Range {
+	last := len(d.lines) - 1
+	return Range{End: Position{Line: last, Character: utf16Len(d.lines[last])}}
+}
+
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16RuneLen(r)
+	}
+	return n
+}
+
+func utf16RuneLen(r rune) int {
+	if r >= 0x10000 && r <= utf8.MaxRune {
+		return 2
+	}
+	return 1
+}
+
+func (d *document) diagnostics() []Diagnostic {
+	diagnostics := []Diagnostic{}
+	for _, err := range d.errors {
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    d.tokenRange(err.Token),
+			Severity: SeverityError,
+			Source:   "interpreter",
+			Message:  err.Message,
+		})
+	}
+	return diagnostics
+}
+
+// identifierAt finds the identifier under the cursor together with the
+// scopes enclosing it, innermost last. Properties of member expressions
+// name module exports and are not returned.
+func (d *document) identifierAt(pos Position) (*ast.Identifier, []ast.Node) {
+	line, column := d.column(pos)
+	var found *ast.Identifier
+	var scopes []ast.Node
+	properties := map[*ast.Identifier]bool{}
+	ast.Inspect(d.program, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case nil:
+			return false
+		case *ast.Program:
+			scopes = append(scopes, n)
+		case *ast.FunctionLiteral:
+			if !d.contains(n, line, column) {
+				return false
+			}
+			scopes = append(scopes, n)
+		case *ast.MemberExpression:
+			properties[n.Property] = true
+		case *ast.Identifier:
+			if properties[n] {
+				return false
+			}
+			t := n.Token
+			if t.Line == line && t.Column <= column && column <= t.Column+len(t.Literal) {
+				found = n
+			}
+		}
+		return found == nil
+	})
+	return found, scopes
+}
+
+func (d *document) contains(node ast.Node, line, column int) bool {
+	first, last := ast.Span(node)
+	if line < first.Line || line > last.Line {
+		return false
+	}
+	if line == first.Line && column < first.Column {
+		return false
+	}
+	if line == last.Line && column > last.Column+len(last.Literal) {
+		return false
+	}
+	return true
+}
+
+// declaration is a name bound by a let statement or a parameter.
+type declaration struct {
+	name  *ast.Identifier
+	let   *ast.LetStatement // nil for parameters
+	scope ast.Node
+}
+
+// declarations lists the names bound directly in scope, which is the
+// program or a function literal. Bindings in nested functions belong to
+// those functions.
+func declarations(scope ast.Node) []declaration {
+	decls := []declaration{}
+	var body ast.Node = scope
+	if fl, ok := scope.(*ast.FunctionLiteral); ok {
+		for _, param := range fl.Parameters {
+			decls = append(decls, declaration{name: param, scope: scope})
+		}
+		if fl.Body == nil {
+			return decls
+		}
+		body = fl.Body
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FunctionLiteral:
+			return false
+		case *ast.LetStatement:
+			if n.Name != nil {
+				decls = append(decls, declaration{name: n.Name, let: n, scope: scope})
+			}
+		}
+		return n != nil
+	})
+	return decls
+}
+
+// resolve finds the declaration an identifier refers to: the closest
+// declaration in the innermost scope binding the name, preferring the
+// last one before the identifier.
+func resolve(ident *ast.Identifier, scopes []ast.Node) (declaration, bool) {
+	for i := len(scopes) - 1; i >= 0; i-- {
+		var before, after *declaration
+		for _, decl := range declarations(scopes[i]) {
+			decl := decl
+			if decl.name.Value != ident.Value {
+				continue
+			}
+			if decl.name == ident {
+				return decl, true
+			}
+			if !tokenBefore(ident.Token, decl.name.Token) {
+				before = &decl
+			} else if after == nil {
+				after = &decl
+			}
+		}
+		if before != nil {
+			return *before, true
+		}
+		if after != nil {
+			return *after, true
+		}
+	}
+	return declaration{}, false
+}
+
+func tokenBefore(a, b token.Token) bool {
+	return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+}
+
+// signature describes a declaration for hovers and completion items.
+func signature(decl declaration) string {
+	if decl.let == nil {
+		return "(parameter) " + decl.name.Value
+	}
+	if fl, ok := decl.let.Value.(*ast.FunctionLiteral); ok {
+		params := []string{}
+		for _, p := range fl.Parameters {
+			params = append(params, p.Value)
+		}
+		return "fn " + decl.name.Value + "(" + strings.Join(params, ", ") + ")"
+	}
+	return "let " + decl.name.Value
+}
+
+func (d *document) symbols(scope ast.Node) []DocumentSymbol {
+	symbols := []DocumentSymbol{}
+	for _, decl := range declarations(scope) {
+		if decl.let == nil {
+			continue
+		}
+		symbol := DocumentSymbol{
+			Name:           decl.name.Value,
+			Detail:         signature(decl),
+			Kind:           SymbolVariable,
+			Range:          d.nodeRange(decl.let),
+			SelectionRange: d.tokenRange(decl.name.Token),
+		}
+		if fl, ok := decl.let.Value.(*ast.FunctionLiteral); ok {
+			symbol.Kind = SymbolFunction
+			symbol.Children = d.symbols(fl)
+		}
+		symbols = append(symbols, symbol)
+	}
+	return symbols
+}
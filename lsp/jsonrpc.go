@@ -0,0 +1,48 @@
+package lsp
+
+import (
+	"encoding/json"
+	"interpreter/framing"
+	"io"
+)
+
+// conn reads and writes JSON-RPC messages.
+type conn struct {
+	r *framing.Reader
+	w *framing.Writer
+}
+
+func newConn(r io.Reader, w io.Writer) *conn {
+	return &conn{r: framing.NewReader(r), w: framing.NewWriter(w)}
+}
+
+func (c *conn) read() (*message, error) {
+	body, err := c.r.Read()
+	if err != nil {
+		return nil, err
+	}
+	msg := &message{}
+	if err := json.Unmarshal(body, msg); err != nil {
+		return nil, &parseError{err}
+	}
+	return msg, nil
+}
+
+// parseError is a message that is not valid JSON-RPC. Unlike a broken
+// header, it leaves the stream readable.
+type parseError struct {
+	err error
+}
+
+func (e *parseError) Error() string {
+	return "parse error: " + e.err.Error()
+}
+
+func (c *conn) write(msg *message) error {
+	msg.JSONRPC = "2.0"
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return c.w.Write(body)
+}
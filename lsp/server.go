@@ -0,0 +1,278 @@
+// Package lsp implements a Language Server Protocol server for scripts.
+package lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"interpreter/ast"
+	"interpreter/evaluator"
+	"interpreter/format"
+	"interpreter/token"
+	"io"
+	"sort"
+)
+
+type Server struct {
+	conn      *conn
+	documents map[string]*document
+	shutdown  bool
+}
+
+func NewServer() *Server {
+	return &Server{documents: make(map[string]*document)}
+}
+
+// Serve handles the messages read from r, writing responses and
+// notifications to w, until the client sends exit or closes r.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.conn = newConn(r, w)
+	for {
+		msg, err := s.conn.read()
+		if err == io.EOF {
+			return nil
+		}
+		var perr *parseError
+		if errors.As(err, &perr) {
+			// the id of the message is unknown, so the reply has a null one
+			id := json.RawMessage("null")
+			resp := &message{ID: &id, Error: &responseError{Code: codeParseError, Message: perr.Error()}}
+			if err := s.conn.write(resp); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return fmt.Errorf("exit before shutdown")
+			}
+			return nil
+		}
+
+		result, rerr := s.handle(msg)
+		if msg.ID == nil {
+			// notifications get no response
+			continue
+		}
+		resp := &message{ID: msg.ID, Error: rerr}
+		if rerr == nil {
+			if resp.Result, err = json.Marshal(result); err != nil {
+				return err
+			}
+		}
+		if err := s.conn.write(resp); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) notify(method string, params interface{}) error {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return s.conn.write(&message{Method: method, Params: raw})
+}
+
+func (s *Server) handle(msg *message) (interface{}, *responseError) {
+	switch msg.Method {
+	case "initialize":
+		return s.initialize(), nil
+	case "initialized":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		s.update(params.TextDocument.URI, params.TextDocument.Text)
+		return nil, nil
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		// the server asks for full syncs, so the last change is the whole text
+		if n := len(params.ContentChanges); n > 0 {
+			s.update(params.TextDocument.URI, params.ContentChanges[n-1].Text)
+		}
+		return nil, nil
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		delete(s.documents, params.TextDocument.URI)
+		return nil, nil
+	case "textDocument/definition":
+		return s.withPosition(msg, s.definition)
+	case "textDocument/hover":
+		return s.withPosition(msg, s.hover)
+	case "textDocument/completion":
+		return s.withPosition(msg, s.completion)
+	case "textDocument/documentSymbol":
+		return s.withDocument(msg, s.documentSymbol)
+	case "textDocument/formatting":
+		return s.withDocument(msg, s.formatting)
+	}
+	return nil, &responseError{Code: codeMethodNotFound, Message: "method not found: " + msg.Method}
+}
+
+func invalidParams(err error) *responseError {
+	return &responseError{Code: codeInvalidParams, Message: err.Error()}
+}
+
+func (s *Server) withPosition(msg *message, fn func(*document, Position) interface{}) (interface{}, *responseError) {
+	var params TextDocumentPositionParams
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		return nil, invalidParams(err)
+	}
+	doc, ok := s.documents[params.TextDocument.URI]
+	if !ok {
+		return nil, nil
+	}
+	return fn(doc, params.Position), nil
+}
+
+func (s *Server) withDocument(msg *message, fn func(*document) interface{}) (interface{}, *responseError) {
+	var params DocumentParams
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		return nil, invalidParams(err)
+	}
+	doc, ok := s.documents[params.TextDocument.URI]
+	if !ok {
+		return nil, nil
+	}
+	return fn(doc), nil
+}
+
+func (s *Server) initialize() *InitializeResult {
+	result := &InitializeResult{
+		Capabilities: ServerCapabilities{
+			TextDocumentSync:           1, // full
+			HoverProvider:              true,
+			DefinitionProvider:         true,
+			CompletionProvider:         struct{}{},
+			DocumentSymbolProvider:     true,
+			DocumentFormattingProvider: true,
+		},
+	}
+	result.ServerInfo.Name = "interpreter"
+	return result
+}
+
+// update parses the new text of a document and publishes its diagnostics.
+func (s *Server) update(uri, text string) {
+	doc := newDocument(uri, text)
+	s.documents[uri] = doc
+	s.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{
+		URI:         uri,
+		Diagnostics: doc.diagnostics(),
+	})
+}
+
+func (s *Server) definition(doc *document, pos Position) interface{} {
+	ident, scopes := doc.identifierAt(pos)
+	if ident == nil {
+		return nil
+	}
+	decl, ok := resolve(ident, scopes)
+	if !ok {
+		return nil
+	}
+	return Location{URI: doc.uri, Range: doc.tokenRange(decl.name.Token)}
+}
+
+func (s *Server) hover(doc *document, pos Position) interface{} {
+	ident, scopes := doc.identifierAt(pos)
+	if ident == nil {
+		return nil
+	}
+	var text string
+	if decl, ok := resolve(ident, scopes); ok {
+		text = signature(decl)
+	} else if builtin, ok := evaluator.GetBuiltin(ident.Value); ok {
+		text = builtinSignature(ident.Value, builtin.MinArgs, builtin.MaxArgs)
+	} else {
+		return nil
+	}
+	return Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: "```\n" + text + "\n```"},
+		Range:    doc.tokenRange(ident.Token),
+	}
+}
+
+func builtinSignature(name string, min, max int) string {
+	switch {
+	case max < 0:
+		return fmt.Sprintf("builtin %s(at least %d arguments)", name, min)
+	case min == max:
+		return fmt.Sprintf("builtin %s(%d arguments)", name, min)
+	default:
+		return fmt.Sprintf("builtin %s(%d to %d arguments)", name, min, max)
+	}
+}
+
+// completion offers the names bound in the scopes around the cursor,
+// the builtins and the keywords.
+func (s *Server) completion(doc *document, pos Position) interface{} {
+	_, scopes := doc.identifierAt(pos)
+	if len(scopes) == 0 {
+		scopes = append(scopes, doc.program)
+	}
+	items := []CompletionItem{}
+	seen := map[string]bool{}
+	for i := len(scopes) - 1; i >= 0; i-- {
+		for _, decl := range declarations(scopes[i]) {
+			if seen[decl.name.Value] {
+				continue
+			}
+			seen[decl.name.Value] = true
+			kind := CompletionVariable
+			if decl.let != nil {
+				if _, ok := decl.let.Value.(*ast.FunctionLiteral); ok {
+					kind = CompletionFunction
+				}
+			}
+			items = append(items, CompletionItem{Label: decl.name.Value, Kind: kind, Detail: signature(decl)})
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Label < items[j].Label })
+
+	for _, name := range evaluator.BuiltinNames() {
+		if seen[name] {
+			continue
+		}
+		builtin, _ := evaluator.GetBuiltin(name)
+		items = append(items, CompletionItem{
+			Label:  name,
+			Kind:   CompletionFunction,
+			Detail: builtinSignature(name, builtin.MinArgs, builtin.MaxArgs),
+		})
+	}
+	for _, keyword := range token.Keywords() {
+		items = append(items, CompletionItem{Label: keyword, Kind: CompletionKeyword})
+	}
+	return items
+}
+
+func (s *Server) documentSymbol(doc *document) interface{} {
+	return doc.symbols(doc.program)
+}
+
+func (s *Server) formatting(doc *document) interface{} {
+	res, err := format.Source([]byte(doc.text))
+	if err != nil {
+		return []TextEdit{}
+	}
+	if string(res) == doc.text {
+		return []TextEdit{}
+	}
+	return []TextEdit{{Range: doc.fullRange(), NewText: string(res)}}
+}
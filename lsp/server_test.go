@@ -0,0 +1,350 @@
+package lsp
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+// client drives a Server in-process over pipes, the way an editor does
+// over stdio.
+type client struct {
+	t             *testing.T
+	conn          *conn
+	nextID        int
+	notifications []*message
+	done          chan error
+}
+
+func newClient(t *testing.T) *client {
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+	c := &client{t: t, conn: newConn(clientIn, clientOut), done: make(chan error, 1)}
+	go func() {
+		err := NewServer().Serve(serverIn, serverOut)
+		serverOut.Close()
+		c.done <- err
+	}()
+	return c
+}
+
+func (c *client) call(method string, params interface{}, result interface{}) {
+	c.t.Helper()
+	c.nextID++
+	id := json.RawMessage(strings.TrimSpace(string(mustMarshal(c.t, c.nextID))))
+	if err := c.conn.write(&message{ID: &id, Method: method, Params: mustMarshal(c.t, params)}); err != nil {
+		c.t.Fatalf("write %s: %s", method, err)
+	}
+	for {
+		msg, err := c.conn.read()
+		if err != nil {
+			c.t.Fatalf("read response to %s: %s", method, err)
+		}
+		if msg.ID == nil {
+			c.notifications = append(c.notifications, msg)
+			continue
+		}
+		if msg.Error != nil {
+			c.t.Fatalf("%s failed: %s", method, msg.Error.Message)
+		}
+		if result != nil {
+			if err := json.Unmarshal(msg.Result, result); err != nil {
+				c.t.Fatalf("decode result of %s: %s", method, err)
+			}
+		}
+		return
+	}
+}
+
+func (c *client) notify(method string, params interface{}) {
+	c.t.Helper()
+	if err := c.conn.write(&message{Method: method, Params: mustMarshal(c.t, params)}); err != nil {
+		c.t.Fatalf("write %s: %s", method, err)
+	}
+}
+
+// diagnostics waits for the next published diagnostics.
+func (c *client) diagnostics() PublishDiagnosticsParams {
+	c.t.Helper()
+	var msg *message
+	if len(c.notifications) > 0 {
+		msg, c.notifications = c.notifications[0], c.notifications[1:]
+	} else {
+		var err error
+		if msg, err = c.conn.read(); err != nil {
+			c.t.Fatalf("read notification: %s", err)
+		}
+	}
+	if msg.Method != "textDocument/publishDiagnostics" {
+		c.t.Fatalf("unexpected message %s", msg.Method)
+	}
+	var params PublishDiagnosticsParams
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		c.t.Fatal(err)
+	}
+	return params
+}
+
+func mustMarshal(t *testing.T, v interface{}) json.RawMessage {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+const uri = "file:///tmp/test.tl"
+
+func startSession(t *testing.T, text string) *client {
+	c := newClient(t)
+	var init InitializeResult
+	c.call("initialize", map[string]interface{}{}, &init)
+	if !init.Capabilities.DefinitionProvider || init.Capabilities.TextDocumentSync != 1 {
+		t.Fatalf("wrong capabilities. got=%+v", init.Capabilities)
+	}
+	c.notify("initialized", struct{}{})
+	c.notify("textDocument/didOpen", DidOpenTextDocumentParams{
+		TextDocument: TextDocumentItem{URI: uri, LanguageID: "interpreter", Version: 1, Text: text},
+	})
+	return c
+}
+
+func (c *client) shutdown() {
+	c.t.Helper()
+	c.call("shutdown", nil, nil)
+	c.notify("exit", nil)
+	if err := <-c.done; err != nil {
+		c.t.Errorf("server returned error: %s", err)
+	}
+}
+
+func position(uri string, line, character int) TextDocumentPositionParams {
+	return TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     Position{Line: line, Character: character},
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	c := startSession(t, "let x = 1;\nlet = 5;")
+
+	diags := c.diagnostics()
+	if diags.URI != uri {
+		t.Errorf("wrong uri. got=%q", diags.URI)
+	}
+	if len(diags.Diagnostics) == 0 {
+		t.Fatalf("no diagnostics published")
+	}
+	d := diags.Diagnostics[0]
+	expected := Range{Start: Position{Line: 1, Character: 4}, End: Position{Line: 1, Character: 5}}
+	if d.Range != expected || d.Severity != SeverityError {
+		t.Errorf("wrong diagnostic. got=%+v", d)
+	}
+	if d.Message != "expected next token to be IDENT, but got =" {
+		t.Errorf("wrong message. got=%q", d.Message)
+	}
+
+	c.notify("textDocument/didChange", DidChangeTextDocumentParams{
+		TextDocument:   TextDocumentIdentifier{URI: uri},
+		ContentChanges: []TextDocumentContentChangeEvent{{Text: "let x = 1;"}},
+	})
+	if diags := c.diagnostics(); len(diags.Diagnostics) != 0 {
+		t.Errorf("diagnostics not cleared. got=%+v", diags.Diagnostics)
+	}
+	c.shutdown()
+}
+
+const sample = `let total = 0;
+let add = fn(a, b) {
+	let res = a + b;
+	res
+};
+add(total, "é😀"); len("x");`
+
+func TestDefinition(t *testing.T) {
+	c := startSession(t, sample)
+	c.diagnostics()
+
+	tests := []struct {
+		line, character int
+		expected        *Range
+	}{
+		// res inside the body -> let res
+		{3, 1, &Range{Start: Position{Line: 2, Character: 5}, End: Position{Line: 2, Character: 8}}},
+		// a -> parameter a
+		{2, 11, &Range{Start: Position{Line: 1, Character: 13}, End: Position{Line: 1, Character: 14}}},
+		// add call -> let add
+		{5, 0, &Range{Start: Position{Line: 1, Character: 4}, End: Position{Line: 1, Character: 7}}},
+		// total argument -> let total
+		{5, 6, &Range{Start: Position{Line: 0, Character: 4}, End: Position{Line: 0, Character: 9}}},
+		// builtins have no definition
+		{5, 20, nil},
+	}
+	for _, tt := range tests {
+		var loc *Location
+		c.call("textDocument/definition", position(uri, tt.line, tt.character), &loc)
+		if tt.expected == nil {
+			if loc != nil {
+				t.Errorf("expected no definition at %d:%d. got=%+v", tt.line, tt.character, loc)
+			}
+			continue
+		}
+		if loc == nil {
+			t.Errorf("no definition at %d:%d", tt.line, tt.character)
+			continue
+		}
+		if loc.URI != uri || loc.Range != *tt.expected {
+			t.Errorf("wrong definition at %d:%d. expected=%+v, got=%+v",
+				tt.line, tt.character, *tt.expected, loc.Range)
+		}
+	}
+	c.shutdown()
+}
+
+func TestHover(t *testing.T) {
+	c := startSession(t, sample)
+	c.diagnostics()
+
+	tests := []struct {
+		line, character int
+		expected        string
+	}{
+		{5, 1, "fn add(a, b)"},
+		{3, 1, "let res"},
+		{2, 15, "(parameter) b"},
+		{5, 20, "builtin len(1 arguments)"},
+	}
+	for _, tt := range tests {
+		var hover *Hover
+		c.call("textDocument/hover", position(uri, tt.line, tt.character), &hover)
+		if hover == nil {
+			t.Errorf("no hover at %d:%d", tt.line, tt.character)
+			continue
+		}
+		expected := "```\n" + tt.expected + "\n```"
+		if hover.Contents.Value != expected {
+			t.Errorf("wrong hover at %d:%d. expected=%q, got=%q",
+				tt.line, tt.character, expected, hover.Contents.Value)
+		}
+	}
+	c.shutdown()
+}
+
+func TestCompletion(t *testing.T) {
+	c := startSession(t, sample)
+	c.diagnostics()
+
+	var items []CompletionItem
+	c.call("textDocument/completion", position(uri, 3, 1), &items)
+	labels := map[string]int{}
+	for _, item := range items {
+		labels[item.Label] = item.Kind
+	}
+	expected := map[string]int{
+		"res":   CompletionVariable,
+		"a":     CompletionVariable,
+		"b":     CompletionVariable,
+		"add":   CompletionFunction,
+		"total": CompletionVariable,
+		"len":   CompletionFunction,
+		"echo":  CompletionFunction,
+		"let":   CompletionKeyword,
+		"fn":    CompletionKeyword,
+	}
+	for label, kind := range expected {
+		if got, ok := labels[label]; !ok || got != kind {
+			t.Errorf("missing completion %q of kind %d. got kind=%d", label, kind, got)
+		}
+	}
+
+	// outside the function its bindings are out of scope
+	items = nil
+	c.call("textDocument/completion", position(uri, 0, 0), &items)
+	for _, item := range items {
+		if item.Label == "res" || item.Label == "a" {
+			t.Errorf("completion %q offered out of scope", item.Label)
+		}
+	}
+	c.shutdown()
+}
+
+func TestDocumentSymbol(t *testing.T) {
+	c := startSession(t, sample)
+	c.diagnostics()
+
+	var symbols []DocumentSymbol
+	c.call("textDocument/documentSymbol", DocumentParams{TextDocument: TextDocumentIdentifier{URI: uri}}, &symbols)
+	if len(symbols) != 2 {
+		t.Fatalf("wrong number of symbols. got=%+v", symbols)
+	}
+	if symbols[0].Name != "total" || symbols[0].Kind != SymbolVariable {
+		t.Errorf("wrong first symbol. got=%+v", symbols[0])
+	}
+	add := symbols[1]
+	if add.Name != "add" || add.Kind != SymbolFunction || add.Detail != "fn add(a, b)" {
+		t.Errorf("wrong second symbol. got=%+v", add)
+	}
+	if add.Range.Start.Line != 1 || add.Range.End.Line != 4 {
+		t.Errorf("wrong range of add. got=%+v", add.Range)
+	}
+	if len(add.Children) != 1 || add.Children[0].Name != "res" {
+		t.Errorf("wrong children of add. got=%+v", add.Children)
+	}
+	c.shutdown()
+}
+
+func TestFormatting(t *testing.T) {
+	c := startSession(t, "let x=1+2\nlet y = fn(a){a}")
+	c.diagnostics()
+
+	var edits []TextEdit
+	c.call("textDocument/formatting", DocumentParams{TextDocument: TextDocumentIdentifier{URI: uri}}, &edits)
+	if len(edits) != 1 {
+		t.Fatalf("wrong number of edits. got=%+v", edits)
+	}
+	expected := "let x = 1 + 2;\nlet y = fn(a) {\n\ta;\n};\n"
+	if edits[0].NewText != expected {
+		t.Errorf("wrong new text. expected=%q, got=%q", expected, edits[0].NewText)
+	}
+	full := Range{End: Position{Line: 1, Character: 16}}
+	if edits[0].Range != full {
+		t.Errorf("edit does not cover the document. got=%+v", edits[0].Range)
+	}
+	c.shutdown()
+}
+
+func TestUTF16Positions(t *testing.T) {
+	doc := newDocument(uri, `let s = "é😀"; s`)
+	// é is 2 bytes and 1 UTF-16 unit, 😀 is 4 bytes and 2 units
+	if pos := doc.position(1, 19); pos.Character != 15 {
+		t.Errorf("wrong character. expected=15, got=%d", pos.Character)
+	}
+	if _, col := doc.column(Position{Line: 0, Character: 15}); col != 19 {
+		t.Errorf("wrong column. expected=19, got=%d", col)
+	}
+}
+
+func TestParseErrorKeepsSession(t *testing.T) {
+	c := startSession(t, "let a = 1;")
+	c.diagnostics()
+	if err := c.conn.w.Write([]byte(`{"id": 1, "method": `)); err != nil {
+		t.Fatal(err)
+	}
+	body, err := c.conn.r.Read()
+	if err != nil {
+		t.Fatalf("read parse error: %s", err)
+	}
+	if !strings.Contains(string(body), `"id":null`) || !strings.Contains(string(body), `"code":-32700`) {
+		t.Fatalf("wrong response to malformed message. got=%s", body)
+	}
+	c.shutdown()
+}
+
+func TestInvalidContentLength(t *testing.T) {
+	for _, header := range []string{"Content-Length: -1\r\n\r\n", "Content-Length: 99999999999\r\n\r\n"} {
+		if err := NewServer().Serve(strings.NewReader(header), io.Discard); err == nil {
+			t.Errorf("no error for %q", header)
+		}
+	}
+}
@@ -1,13 +1,65 @@
 package main
 
 import (
+	"bytes"
+	"flag"
 	"fmt"
+	"interpreter/dap"
+	"interpreter/debugger"
+	"interpreter/evaluator"
+	"interpreter/format"
+	"interpreter/lint"
+	"interpreter/lsp"
+	"interpreter/object"
 	"interpreter/repl"
+	"io"
 	"os"
 	"os/user"
+	"path/filepath"
+	"strings"
 )
 
+// SEARCH_PATH_ENV lists extra directories to look up imported modules in.
+const SEARCH_PATH_ENV = "INTERPRETER_PATH"
+
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "run":
+			if len(os.Args) != 3 {
+				fmt.Fprintln(os.Stderr, "usage: interpreter run file")
+				os.Exit(2)
+			}
+			os.Exit(run(os.Args[2]))
+		case "fmt":
+			os.Exit(fmtFiles(os.Args[2:]))
+		case "lint":
+			os.Exit(lintFiles(os.Args[2:]))
+		case "debug":
+			if len(os.Args) != 3 {
+				fmt.Fprintln(os.Stderr, "usage: interpreter debug file")
+				os.Exit(2)
+			}
+			os.Exit(debugger.Start(os.Args[2], newRuntime(), os.Stdin, os.Stdout))
+		case "lsp":
+			if err := lsp.NewServer().Serve(os.Stdin, os.Stdout); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			return
+		case "dap":
+			if err := dap.NewServer(newRuntime()).Serve(os.Stdin, os.Stdout); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	flags := flag.NewFlagSet("interpreter", flag.ExitOnError)
+	noColor := flags.Bool("no-color", false, "disable colors in the REPL, as does setting NO_COLOR")
+	flags.Parse(os.Args[1:])
+
 	user, err := user.Current()
 	if err != nil {
 		panic(err)
@@ -15,5 +67,124 @@ func main() {
 	fmt.Printf("Hello %s! This is the little programming language!\n", user.Username)
 	fmt.Println("You can type in command lines")
 	fmt.Println("Happy to enjoy it")
-	repl.Start(os.Stdin, os.Stdout)
+	repl.Start(os.Stdin, os.Stdout, repl.Options{
+		Color:   !*noColor && repl.ColorEnabled(os.Stdout),
+		Runtime: newRuntime,
+	})
+}
+
+// newRuntime creates the runtime scripts and REPL sessions are evaluated
+// with.
+func newRuntime() *object.Runtime {
+	rt := object.NewRuntime()
+	rt.SearchPath = filepath.SplitList(os.Getenv(SEARCH_PATH_ENV))
+	return rt
+}
+
+// run evaluates a script file and reports the error it raised, if any.
+func run(path string) int {
+	if result := evaluator.LoadFile(path, newRuntime()); result != nil && result.Type() == object.ERROR_OBJ {
+		fmt.Fprintln(os.Stderr, result.Inspect())
+		return 1
+	}
+	return 0
+}
+
+// fmtFiles formats the given files, or stdin if there are none, and
+// prints the result or rewrites the files in place with -w.
+func fmtFiles(args []string) int {
+	flags := flag.NewFlagSet("fmt", flag.ExitOnError)
+	write := flags.Bool("w", false, "write result to the source file instead of stdout")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: interpreter fmt [-w] [files...]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		src, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		res, err := format.Source(src)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "<stdin>: %s\n", err)
+			return 1
+		}
+		os.Stdout.Write(res)
+		return 0
+	}
+
+	status := 0
+	for _, path := range flags.Args() {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			status = 1
+			continue
+		}
+		res, err := format.Source(src)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", path, err)
+			status = 1
+			continue
+		}
+		if !*write {
+			os.Stdout.Write(res)
+			continue
+		}
+		if bytes.Equal(src, res) {
+			continue
+		}
+		if err := os.WriteFile(path, res, 0644); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			status = 1
+		}
+	}
+	return status
+}
+
+// lintFiles reports the lint findings of the given files as
+// file:line:column: message (rule).
+func lintFiles(args []string) int {
+	flags := flag.NewFlagSet("lint", flag.ExitOnError)
+	disable := flags.String("disable", "", "comma-separated rule IDs not to report: "+strings.Join(lint.Rules, ", "))
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: interpreter lint [-disable rules] files...")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	config := lint.Config{Disabled: map[string]bool{}}
+	for _, rule := range strings.Split(*disable, ",") {
+		if rule = strings.TrimSpace(rule); rule != "" {
+			config.Disabled[rule] = true
+		}
+	}
+
+	status := 0
+	for _, path := range flags.Args() {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			status = 1
+			continue
+		}
+		findings, err := lint.Source(src, config)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", path, err)
+			status = 1
+			continue
+		}
+		for _, f := range findings {
+			fmt.Printf("%s:%s\n", path, f)
+			status = 1
+		}
+	}
+	return status
 }
@@ -1,21 +1,43 @@
 package object
 
+import "sort"
+
 type Environment struct {
-	store map[string]Object
-	outer *Environment
+	store   map[string]Object
+	outer   *Environment
+	runtime *Runtime
+	file    string   //source file the environment belongs to, "" for the REPL
+	exports []string //names declared with `export`, in order
 }
 
 func NewEnclosedEnvironment(o *Environment) *Environment {
 	env := &Environment{
-		store: make(map[string]Object),
-		outer: o,
+		store:   make(map[string]Object),
+		outer:   o,
+		runtime: o.runtime,
+		file:    o.file,
 	}
 	return env
 }
 
 func NewEnvironment() *Environment {
 	s := make(map[string]Object)
-	return &Environment{store: s, outer: nil}
+	return &Environment{store: s, outer: nil, runtime: NewRuntime()}
+}
+
+// NewModuleEnvironment returns a top-level environment for the source file
+// at path which shares the runtime of an existing interpreter.
+func NewModuleEnvironment(rt *Runtime, path string) *Environment {
+	s := make(map[string]Object)
+	return &Environment{store: s, outer: nil, runtime: rt, file: path}
+}
+
+// NewFileEnvironment returns an environment enclosed in o for evaluating
+// the source file at path, whose imports are resolved relative to it.
+func NewFileEnvironment(o *Environment, path string) *Environment {
+	env := NewEnclosedEnvironment(o)
+	env.file = path
+	return env
 }
 
 func (e *Environment) Get(key string) (Object, bool) {
@@ -30,3 +52,46 @@ func (e *Environment) Set(key string, value Object) Object {
 	e.store[key] = value
 	return value
 }
+
+// Names returns the names bound in this environment, not in the
+// environments enclosing it, in sorted order.
+func (e *Environment) Names() []string {
+	names := make([]string, 0, len(e.store))
+	for name := range e.store {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Outer returns the enclosing environment, nil for a top-level one.
+func (e *Environment) Outer() *Environment {
+	return e.outer
+}
+
+func (e *Environment) Runtime() *Runtime {
+	return e.runtime
+}
+
+func (e *Environment) File() string {
+	return e.file
+}
+
+// Export marks a binding of this environment as visible to importers.
+func (e *Environment) Export(key string) {
+	for _, name := range e.exports {
+		if name == key {
+			return
+		}
+	}
+	e.exports = append(e.exports, key)
+}
+
+// Exports collects the exported bindings of this environment.
+func (e *Environment) Exports() map[string]Object {
+	exports := make(map[string]Object, len(e.exports))
+	for _, name := range e.exports {
+		exports[name] = e.store[name]
+	}
+	return exports
+}
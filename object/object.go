@@ -3,8 +3,13 @@ package object
 import (
 	"bytes"
 	"fmt"
+	"hash/fnv"
 	"interpreter/ast"
+	"math/big"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 )
 
 /*
@@ -20,6 +25,7 @@ type Object interface {
 
 const (
 	INTEGER_OBJ      = "INTEGER"
+	FLOAT_OBJ        = "FLOAT"
 	BOOLEAN_OBJ      = "BOOLEAN"
 	NULL_OBJ         = "NULL"
 	RETURN_VALUE_OBJ = "RETURN_VALUE"
@@ -27,7 +33,13 @@ const (
 	FUNCTION_OBJ     = "FUNCTION"
 	STRING_OBJ       = "STRING"
 
+	ARRAY_OBJ = "ARRAY"
+	HASH_OBJ  = "HASH"
+	REGEX_OBJ = "REGEX"
+	TIME_OBJ  = "TIME"
+
 	BUILTIN_OBJ = "BUILTIN"
+	MODULE_OBJ  = "MODULE"
 )
 
 type Integer struct {
@@ -37,6 +49,32 @@ type Integer struct {
 func (i *Integer) Type() ObjectType { return INTEGER_OBJ }
 func (i *Integer) Inspect() string  { return fmt.Sprintf("%d", i.Value) }
 
+// BigInteger is an integer that does not fit an int64. It has the type
+// of Integer, and arithmetic returns an Integer for every result that
+// fits, so the two never hold the same value.
+type BigInteger struct {
+	Value *big.Int
+}
+
+func (b *BigInteger) Type() ObjectType { return INTEGER_OBJ }
+func (b *BigInteger) Inspect() string  { return b.Value.String() }
+
+type Float struct {
+	Value float64
+}
+
+func (f *Float) Type() ObjectType { return FLOAT_OBJ }
+
+// Inspect always shows a fraction or an exponent, so that floats are
+// told apart from integers.
+func (f *Float) Inspect() string {
+	s := strconv.FormatFloat(f.Value, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eIN") {
+		s += ".0"
+	}
+	return s
+}
+
 type Boolean struct {
 	Value bool
 }
@@ -58,6 +96,8 @@ func (rv *ReturnValue) Inspect() string  { return rv.Value.Inspect() }
 
 type Error struct {
 	Message string
+	Node    ast.Node //innermost node the error was raised at, nil if unknown
+	File    string   //source file of Node, "" for the REPL
 }
 
 func (e *Error) Type() ObjectType { return ERROR_OBJ }
@@ -92,11 +132,173 @@ type String struct {
 func (s *String) Type() ObjectType { return STRING_OBJ }
 func (s *String) Inspect() string  { return s.Value }
 
-type BuiltinFunction func(args ...Object) Object
+// BuiltinFunction is called with the environment of the call, which
+// gives access to the runtime of the interpreter.
+type BuiltinFunction func(env *Environment, args ...Object) Object
 
 type Builtin struct {
 	Fn BuiltinFunction
+	// MinArgs and MaxArgs bound the number of arguments Fn is called with,
+	// MaxArgs < 0 means any number. Calls outside them never reach Fn.
+	MinArgs int
+	MaxArgs int
 }
 
 func (b *Builtin) Type() ObjectType { return BUILTIN_OBJ }
 func (b *Builtin) Inspect() string  { return "builtin function" }
+
+// Module is the result of evaluating `import "path"`.
+// Only the bindings declared with `export` are visible through it.
+type Module struct {
+	Path    string
+	Exports map[string]Object
+}
+
+func (m *Module) Type() ObjectType { return MODULE_OBJ }
+func (m *Module) Inspect() string  { return "module(" + m.Path + ")" }
+
+// Regex is a compiled regular expression, with the pattern and flags it
+// was written with.
+type Regex struct {
+	Value   *regexp.Regexp
+	Pattern string
+	Flags   string
+}
+
+func (r *Regex) Type() ObjectType { return REGEX_OBJ }
+
+// Inspect shows the regex as a literal, escaping the slashes of patterns
+// given to `regex`.
+func (r *Regex) Inspect() string {
+	var out bytes.Buffer
+	out.WriteString("/")
+	for i := 0; i < len(r.Pattern); i++ {
+		switch c := r.Pattern[i]; c {
+		case '\\':
+			out.WriteByte(c)
+			if i+1 < len(r.Pattern) {
+				i++
+				out.WriteByte(r.Pattern[i])
+			}
+		case '/':
+			out.WriteString(`\/`)
+		default:
+			out.WriteByte(c)
+		}
+	}
+	out.WriteString("/" + r.Flags)
+	return out.String()
+}
+
+// Time is an instant with the location it is shown in. Durations are
+// integers counting milliseconds.
+type Time struct {
+	Value time.Time
+}
+
+func (t *Time) Type() ObjectType { return TIME_OBJ }
+func (t *Time) Inspect() string  { return t.Value.Format(time.RFC3339Nano) }
+
+type Array struct {
+	Elements []Object
+}
+
+func (a *Array) Type() ObjectType { return ARRAY_OBJ }
+func (a *Array) Inspect() string {
+	elements := []string{}
+	for _, el := range a.Elements {
+		elements = append(elements, InspectElement(el))
+	}
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+
+// InspectElement shows a value held in a collection: strings are quoted
+// so that "1" and 1 can be told apart.
+func InspectElement(obj Object) string {
+	if s, ok := obj.(*String); ok {
+		return `"` + s.Value + `"`
+	}
+	return obj.Inspect()
+}
+
+type HashKey struct {
+	Type  ObjectType
+	Value uint64
+	Big   string //decimal form of a big integer key, which Value cannot hold
+}
+
+// Hashable is implemented by the values that can be hash keys.
+type Hashable interface {
+	Object
+	HashKey() HashKey
+}
+
+func (i *Integer) HashKey() HashKey {
+	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
+}
+
+func (b *BigInteger) HashKey() HashKey {
+	return HashKey{Type: b.Type(), Big: b.Value.String()}
+}
+
+func (b *Boolean) HashKey() HashKey {
+	var value uint64
+	if b.Value {
+		value = 1
+	}
+	return HashKey{Type: b.Type(), Value: value}
+}
+
+func (s *String) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(s.Value))
+	return HashKey{Type: s.Type(), Value: h.Sum64()}
+}
+
+type HashPair struct {
+	Key   Hashable
+	Value Object
+}
+
+// Hash keeps its pairs in the order their keys were first set.
+type Hash struct {
+	Pairs map[HashKey]HashPair
+	Keys  []HashKey
+}
+
+func NewHash() *Hash {
+	return &Hash{Pairs: map[HashKey]HashPair{}}
+}
+
+func (h *Hash) Type() ObjectType { return HASH_OBJ }
+func (h *Hash) Inspect() string {
+	pairs := []string{}
+	for _, pair := range h.Ordered() {
+		pairs = append(pairs, InspectElement(pair.Key)+": "+InspectElement(pair.Value))
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+// Get returns the value set for key.
+func (h *Hash) Get(key Hashable) (Object, bool) {
+	pair, ok := h.Pairs[key.HashKey()]
+	return pair.Value, ok
+}
+
+// Set sets the value for key; a key set again keeps its place.
+func (h *Hash) Set(key Hashable, value Object) {
+	hashKey := key.HashKey()
+	if _, ok := h.Pairs[hashKey]; !ok {
+		h.Keys = append(h.Keys, hashKey)
+	}
+	h.Pairs[hashKey] = HashPair{Key: key, Value: value}
+}
+
+// Ordered returns the pairs of the hash in order.
+func (h *Hash) Ordered() []HashPair {
+	pairs := make([]HashPair, len(h.Keys))
+	for i, key := range h.Keys {
+		pairs[i] = h.Pairs[key]
+	}
+	return pairs
+}
@@ -0,0 +1,78 @@
+package object
+
+import (
+	"context"
+	"interpreter/ast"
+	"io"
+	"math/rand"
+	"os"
+	"time"
+)
+
+// Hook is called by the evaluator before each statement and each call
+// expression, with the environment it is evaluated in and the number of
+// function calls in progress.
+type Hook func(node ast.Node, env *Environment, depth int)
+
+// Clock is where the time builtins get the time from.
+type Clock interface {
+	Now() time.Time
+	// After sends the time on the returned channel once d has elapsed.
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Runtime holds the state shared by every environment of one interpreter:
+// the top-level environment, the environments enclosed by it and the
+// environments of the modules it imports.
+type Runtime struct {
+	// Stdout receives the output of scripts, such as echo's.
+	Stdout io.Writer
+
+	// SearchPath lists the directories tried, in order, for imports that
+	// are not found next to the importing file.
+	SearchPath []string
+
+	// Modules caches evaluated modules by absolute path.
+	Modules map[string]*Module
+	// Loading is the stack of modules currently being evaluated, used to
+	// detect import cycles.
+	Loading []string
+
+	// FileRoot, if set, confines the file builtins to that directory and
+	// what it contains; relative paths are then resolved against it
+	// rather than the working directory. Imports are confined to it and
+	// the search path. FilesDisabled turns the file builtins and imports
+	// of files off altogether.
+	FileRoot      string
+	FilesDisabled bool
+
+	// Clock gives the time to the time builtins; hosts replace the system
+	// clock to make scripts deterministic.
+	Clock Clock
+	// Rand is the random number generator of the random builtins, seeded
+	// from the time unless a script calls seed.
+	Rand *rand.Rand
+	// Context, if set, cancels the evaluation once it is done: the
+	// statement or call about to be evaluated, or a sleep in progress,
+	// fails with an error.
+	Context context.Context
+
+	// Hook, if set, observes the evaluation; debuggers use it.
+	Hook Hook
+	// Depth is the number of function calls in progress.
+	Depth int
+}
+
+func NewRuntime() *Runtime {
+	return &Runtime{
+		Stdout:  os.Stdout,
+		Modules: make(map[string]*Module),
+		Clock:   systemClock{},
+		Rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
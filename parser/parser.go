@@ -5,23 +5,33 @@ import (
 	"interpreter/ast"
 	"interpreter/lexer"
 	"interpreter/token"
+	"math/big"
 	"strconv"
+	"strings"
 )
 
 //The blank identifier _ takes the zero value and
-//the following constants get assigned the values 1 to 7.
+//the following constants get assigned the values 1 to 15.
 //Which numbers we use doesn’t matter, but the order matters.
 // it means precedence. for example '+' < '*', and function call
 //has the heightest precedence.
 const (
 	_           int = iota
 	LOWEST          //default lowest precedence
+	CONDITIONAL     //c ? a : b
+	NULLISH         //??
+	BIT_OR          //|
+	BIT_XOR         //^
+	BIT_AND         //&
 	EQUALS          //== or !=
 	LESSGREATER     //> or <
+	SHIFT           //<< or >>
 	SUM             //+
-	PRODUCT         //* or /
+	PRODUCT         //* or / or %
 	PREFIX          //!x or -x...
+	POWER           //** binds tighter than a prefix on its left: -2 ** 2 is -(2 ** 2)
 	CALL            //myfunc(x)
+	INDEX           //array[index] or lib.name
 )
 
 // the parsing functions are
@@ -32,15 +42,27 @@ type (
 )
 
 var precedences = map[token.TokenType]int{
-	token.EQ:       EQUALS,
-	token.NOT_EQ:   EQUALS,
-	token.LT:       LESSGREATER,
-	token.GT:       LESSGREATER,
-	token.PLUS:     SUM,
-	token.MINUS:    SUM,
-	token.SLASH:    PRODUCT,
-	token.ASTERISK: PRODUCT,
-	token.LPAREN:   CALL,
+	token.QUESTION:     CONDITIONAL,
+	token.NULLISH:      NULLISH,
+	token.PIPE:         BIT_OR,
+	token.CARET:        BIT_XOR,
+	token.AMPERSAND:    BIT_AND,
+	token.EQ:           EQUALS,
+	token.NOT_EQ:       EQUALS,
+	token.LT:           LESSGREATER,
+	token.GT:           LESSGREATER,
+	token.SHL:          SHIFT,
+	token.SHR:          SHIFT,
+	token.PLUS:         SUM,
+	token.MINUS:        SUM,
+	token.SLASH:        PRODUCT,
+	token.ASTERISK:     PRODUCT,
+	token.PERCENT:      PRODUCT,
+	token.POWER:        POWER,
+	token.LPAREN:       CALL,
+	token.LBRACKET:     INDEX,
+	token.DOT:          INDEX,
+	token.QUESTION_DOT: INDEX,
 }
 
 // we need to look at the curToken, which is the current token under
@@ -52,7 +74,7 @@ type Parser struct {
 	peekToken token.Token
 
 	//error
-	errors []string
+	errors []Error
 
 	//In order for our parser to get the correct prefixParseFn or infixParseFn for the current token type
 	prefixParseFns map[token.TokenType]prefixParseFn
@@ -62,7 +84,7 @@ type Parser struct {
 func New(l *lexer.Lexer) *Parser {
 	p := &Parser{
 		l:      l,
-		errors: []string{},
+		errors: []Error{},
 	}
 	//init curToken and peekToken
 	p.nextToken()
@@ -73,21 +95,32 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.IDENT, p.parseIdentfier)
 	//Integer
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
+	p.registerPrefix(token.FLOAT, p.parseFloatLiteral)
 	//String
 	p.registerPrefix(token.STRING, p.parseStringLiteral)
+	//Regex
+	p.registerPrefix(token.REGEX, p.parseRegexLiteral)
 	// !
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
 	// -
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
+	// ~
+	p.registerPrefix(token.TILDE, p.parsePrefixExpression)
 	//true and false
 	p.registerPrefix(token.TRUE, p.parseBoolean)
 	p.registerPrefix(token.FALSE, p.parseBoolean)
+	//null
+	p.registerPrefix(token.NULL, p.parseNullLiteral)
 	// ( 2 + 3 )
 	p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
 	// IF Expression
 	p.registerPrefix(token.IF, p.parseIfExpression)
+	// match (x) { 1 => a, _ => b }
+	p.registerPrefix(token.MATCH, p.parseMatchExpression)
 	// Function
 	p.registerPrefix(token.FUNCTION, p.parseFunctionLiteral)
+	// import "lib"
+	p.registerPrefix(token.IMPORT, p.parseImportExpression)
 
 	//for infix func
 	p.infixParseFns = make(map[token.TokenType]infixParseFn)
@@ -107,9 +140,31 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.LT, p.parseInfixExpression)
 	// ">"
 	p.registerInfix(token.GT, p.parseInfixExpression)
+	// "??"
+	p.registerInfix(token.NULLISH, p.parseInfixExpression)
+	// c ? a : b
+	p.registerInfix(token.QUESTION, p.parseConditionalExpression)
+	// "%" and "**"
+	p.registerInfix(token.PERCENT, p.parseInfixExpression)
+	p.registerInfix(token.POWER, p.parseInfixExpression)
+	// "&", "|", "^", "<<" and ">>"
+	p.registerInfix(token.AMPERSAND, p.parseInfixExpression)
+	p.registerInfix(token.PIPE, p.parseInfixExpression)
+	p.registerInfix(token.CARET, p.parseInfixExpression)
+	p.registerInfix(token.SHL, p.parseInfixExpression)
+	p.registerInfix(token.SHR, p.parseInfixExpression)
 
 	// "("
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
+	// "["
+	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
+	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
+	// "{"
+	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
+	// "."
+	p.registerInfix(token.DOT, p.parseMemberExpression)
+	// "?."
+	p.registerInfix(token.QUESTION_DOT, p.parseOptionalExpression)
 
 	return p
 }
@@ -141,6 +196,8 @@ func (p *Parser) parseStatement() ast.Statement {
 		return p.parseLetStatement()
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.EXPORT:
+		return p.parseExportStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
@@ -169,8 +226,8 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 	//cope with expression
 	stmt.Value = p.parseExpression(LOWEST)
 
-	//skip expression until semicolon
-	for !p.curTokenIs(token.SEMICOLON) {
+	//the semicolon is optional, like for expression statements
+	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
 
@@ -188,8 +245,7 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 
 	stmt.ReturnValue = p.parseExpression(LOWEST)
 
-	//skip expression until semicolon
-	for !p.curTokenIs(token.SEMICOLON) {
+	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
 
@@ -263,13 +319,31 @@ func (p *Parser) peekTokenIs(t token.TokenType) bool {
 	return p.peekToken.Type == t
 }
 
+//Error is a parse error together with the token it was found at.
+type Error struct {
+	Token   token.Token
+	Message string
+}
+
 func (p *Parser) Errors() []string {
+	msgs := make([]string, len(p.errors))
+	for i, err := range p.errors {
+		msgs[i] = err.Message
+	}
+	return msgs
+}
+
+//ErrorList returns the parse errors with their positions.
+func (p *Parser) ErrorList() []Error {
 	return p.errors
 }
 
+func (p *Parser) addError(t token.Token, format string, a ...interface{}) {
+	p.errors = append(p.errors, Error{Token: t, Message: fmt.Sprintf(format, a...)})
+}
+
 func (p *Parser) peekError(t token.TokenType) {
-	msg := fmt.Sprintf("expected next token to be %s, but got %s", t, p.peekToken.Type)
-	p.errors = append(p.errors, msg)
+	p.addError(p.peekToken, "expected next token to be %s, but got %s", t, p.peekToken.Type)
 }
 
 func (p *Parser) registerPrefix(tokenType token.TokenType, fn prefixParseFn) {
@@ -286,14 +360,28 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 	}
 	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
 	if err != nil {
-		msg := fmt.Sprintf("could not parse %q as integer", p.curToken.Literal)
-		p.errors = append(p.errors, msg)
-		return nil
+		// too large for an int64: kept as a big integer
+		b, ok := new(big.Int).SetString(p.curToken.Literal, 0)
+		if !ok {
+			p.addError(p.curToken, "could not parse %q as integer", p.curToken.Literal)
+			return nil
+		}
+		literal.Big = b
+		return literal
 	}
 	literal.Value = value
 	return literal
 }
 
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	value, err := strconv.ParseFloat(p.curToken.Literal, 64)
+	if err != nil {
+		p.addError(p.curToken, "could not parse %q as float", p.curToken.Literal)
+		return nil
+	}
+	return &ast.FloatLiteral{Token: p.curToken, Value: value}
+}
+
 /*
 When parsePrefixExpression is called, p.curToken is either of type
 token.BANG or token.MINUS,But in order to correctly parse a prefix
@@ -320,6 +408,9 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	}
 
 	curP := p.curPrecedence()
+	if RightAssociative(p.curToken.Type) {
+		curP--
+	}
 	p.nextToken()
 	expression.Right = p.parseExpression(curP)
 
@@ -327,7 +418,14 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 }
 
 func (p *Parser) noPrefixParseFnError(t token.TokenType) {
-	p.errors = append(p.errors, fmt.Sprintf("no prefix parse function for %s found", t))
+	// the lexer knows what is wrong with the tokens it found malformed
+	for _, err := range p.l.Errors() {
+		if err.Token == p.curToken {
+			p.addError(p.curToken, "%s", err.Message)
+			return
+		}
+	}
+	p.addError(p.curToken, "no prefix parse function for %s found", t)
 }
 
 func (p *Parser) curPrecedence() int {
@@ -337,6 +435,22 @@ func (p *Parser) curPrecedence() int {
 	return LOWEST
 }
 
+//RightAssociative reports whether a chain of the infix operator t
+//groups from the right: 2 ** 3 ** 2 is 2 ** (3 ** 2), and
+//a ? b : c ? d : e is a ? b : (c ? d : e).
+func RightAssociative(t token.TokenType) bool {
+	return t == token.POWER || t == token.QUESTION
+}
+
+//Precedence returns the binding power of the infix operator t,
+//or LOWEST if t is not an infix operator.
+func Precedence(t token.TokenType) int {
+	if precedence, ok := precedences[t]; ok {
+		return precedence
+	}
+	return LOWEST
+}
+
 func (p *Parser) peekPrecedence() int {
 	if precedence, ok := precedences[p.peekToken.Type]; ok {
 		return precedence
@@ -348,6 +462,10 @@ func (p *Parser) parseBoolean() ast.Expression {
 	return &ast.Boolean{Token: p.curToken, Value: p.curTokenIs(token.TRUE)}
 }
 
+func (p *Parser) parseNullLiteral() ast.Expression {
+	return &ast.NullLiteral{Token: p.curToken}
+}
+
 /*
 Think about ( 2 + 3 )
 when encounter ')', p.parseExpression function will return
@@ -396,6 +514,123 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	return expression
 }
 
+//anything goes between ? and :, as between parentheses
+func (p *Parser) parseConditionalExpression(condition ast.Expression) ast.Expression {
+	expression := &ast.ConditionalExpression{
+		Token:     p.curToken,
+		Condition: condition,
+	}
+
+	p.nextToken()
+	expression.Consequence = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.COLON) {
+		return nil
+	}
+	p.nextToken()
+	expression.Alternative = p.parseExpression(CONDITIONAL - 1)
+
+	return expression
+}
+
+func (p *Parser) parseMatchExpression() ast.Expression {
+	expression := &ast.MatchExpression{
+		Token: p.curToken,
+	}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+	p.nextToken()
+	expression.Value = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	// arms are separated by commas, and the last one may have one too
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+		arm := p.parseMatchArm()
+		if arm == nil {
+			return nil
+		}
+		expression.Arms = append(expression.Arms, arm)
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+	expression.Rbrace = p.curToken
+	return expression
+}
+
+//<pattern> | <pattern> if <guard> => <body>, or _ => <body>
+func (p *Parser) parseMatchArm() *ast.MatchArm {
+	arm := &ast.MatchArm{Token: p.curToken}
+
+	if !p.curTokenIs(token.IDENT) || p.curToken.Literal != "_" {
+		for {
+			pattern := p.parsePattern()
+			if pattern == nil {
+				return nil
+			}
+			arm.Patterns = append(arm.Patterns, pattern)
+			if !p.peekTokenIs(token.PIPE) {
+				break
+			}
+			p.nextToken()
+			p.nextToken()
+		}
+	}
+
+	if p.peekTokenIs(token.IF) {
+		p.nextToken()
+		p.nextToken()
+		arm.Guard = p.parseExpression(LOWEST)
+	}
+
+	if !p.expectPeek(token.ARROW) {
+		return nil
+	}
+	p.nextToken()
+	arm.Body = p.parseExpression(LOWEST)
+
+	return arm
+}
+
+//a literal, or a negative number; '|' separates alternatives instead of
+//being an operator
+func (p *Parser) parsePattern() ast.Expression {
+	tok := p.curToken
+	pattern := p.parseExpression(BIT_OR)
+	switch exp := pattern.(type) {
+	case nil:
+		return nil
+	case *ast.IntegerLiteral, *ast.FloatLiteral, *ast.StringLiteral, *ast.Boolean, *ast.NullLiteral:
+		return pattern
+	case *ast.PrefixExpression:
+		switch exp.Right.(type) {
+		case nil:
+			return nil
+		case *ast.IntegerLiteral, *ast.FloatLiteral:
+			if exp.Operator == "-" {
+				return pattern
+			}
+		}
+	}
+	p.addError(tok, "expected a literal pattern, got %s", pattern.String())
+	return nil
+}
+
 func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	block := &ast.BlockStatement{
 		Token:      p.curToken,
@@ -410,6 +645,7 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 		}
 		p.nextToken()
 	}
+	block.Rbrace = p.curToken
 
 	return block
 }
@@ -476,33 +712,86 @@ func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 		Token:    p.curToken,
 		Function: function,
 	}
-	exp.Arguments = p.parseCallArguments()
+	exp.Arguments = p.parseExpressionList(token.RPAREN)
+	exp.Rparen = p.curToken
 	return exp
 }
 
-func (p *Parser) parseCallArguments() []ast.Expression {
-	args := []ast.Expression{}
+//parses comma-separated expressions up to the end token
+func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
+	list := []ast.Expression{}
 
-	if p.peekTokenIs(token.RPAREN) {
+	if p.peekTokenIs(end) {
 		p.nextToken()
-		return args
+		return list
 	}
 
 	p.nextToken()
 
-	args = append(args, p.parseExpression(LOWEST))
+	list = append(list, p.parseExpression(LOWEST))
 
 	for p.peekTokenIs(token.COMMA) {
 		p.nextToken()
 		p.nextToken()
-		args = append(args, p.parseExpression(LOWEST))
+		list = append(list, p.parseExpression(LOWEST))
 	}
 
-	if !p.expectPeek(token.RPAREN) {
+	if !p.expectPeek(end) {
 		return nil
 	}
 
-	return args
+	return list
+}
+
+func (p *Parser) parseArrayLiteral() ast.Expression {
+	array := &ast.ArrayLiteral{Token: p.curToken}
+	array.Elements = p.parseExpressionList(token.RBRACKET)
+	array.Rbracket = p.curToken
+	return array
+}
+
+//parses {key: value, ...}, like lists a trailing comma is not allowed
+func (p *Parser) parseHashLiteral() ast.Expression {
+	hash := &ast.HashLiteral{Token: p.curToken}
+
+	if p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+		hash.Rbrace = p.curToken
+		return hash
+	}
+
+	for {
+		p.nextToken()
+		key := p.parseExpression(LOWEST)
+		if !p.expectPeek(token.COLON) {
+			return nil
+		}
+		p.nextToken()
+		hash.Keys = append(hash.Keys, key)
+		hash.Values = append(hash.Values, p.parseExpression(LOWEST))
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+	hash.Rbrace = p.curToken
+	return hash
+}
+
+//splits /pattern/flags at its last '/'
+func (p *Parser) parseRegexLiteral() ast.Expression {
+	literal := p.curToken.Literal
+	end := strings.LastIndex(literal, "/")
+	return &ast.RegexLiteral{
+		Token:   p.curToken,
+		Pattern: literal[1:end],
+		Flags:   literal[end+1:],
+	}
 }
 
 func (p *Parser) parseStringLiteral() ast.Expression {
@@ -511,3 +800,102 @@ func (p *Parser) parseStringLiteral() ast.Expression {
 		Value: p.curToken.Literal,
 	}
 }
+
+//parses left[index], or the slice left[low:high] where both bounds are
+//optional
+func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
+	tok := p.curToken
+	var index ast.Expression
+	if !p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		index = p.parseExpression(LOWEST)
+	}
+	if !p.peekTokenIs(token.COLON) {
+		if !p.expectPeek(token.RBRACKET) {
+			return nil
+		}
+		return &ast.IndexExpression{Token: tok, Left: left, Index: index, Rbracket: p.curToken}
+	}
+	p.nextToken()
+	exp := &ast.SliceExpression{Token: tok, Left: left, Low: index}
+	if !p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
+		exp.High = p.parseExpression(LOWEST)
+	}
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+	exp.Rbracket = p.curToken
+	return exp
+}
+
+func (p *Parser) parseMemberExpression(object ast.Expression) ast.Expression {
+	exp := &ast.MemberExpression{
+		Token:  p.curToken,
+		Object: object,
+	}
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	exp.Property = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	return exp
+}
+
+//a?.(args), a?.[index] or a?.name, which are null when a is
+func (p *Parser) parseOptionalExpression(left ast.Expression) ast.Expression {
+	tok := p.curToken
+	switch p.peekToken.Type {
+	case token.LPAREN:
+		p.nextToken()
+		exp := p.parseCallExpression(left).(*ast.CallExpression)
+		exp.Token, exp.Optional = tok, true
+		return exp
+	case token.LBRACKET:
+		p.nextToken()
+		switch exp := p.parseIndexExpression(left).(type) {
+		case *ast.IndexExpression:
+			exp.Token, exp.Optional = tok, true
+			return exp
+		case *ast.SliceExpression:
+			exp.Token, exp.Optional = tok, true
+			return exp
+		}
+		return nil
+	case token.IDENT:
+		p.nextToken()
+		return &ast.MemberExpression{
+			Token:    tok,
+			Object:   left,
+			Property: &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal},
+			Optional: true,
+		}
+	}
+	p.addError(p.peekToken, "expected (, [ or a name after ?., got %s", p.peekToken.Type)
+	return nil
+}
+
+func (p *Parser) parseImportExpression() ast.Expression {
+	exp := &ast.ImportExpression{
+		Token: p.curToken,
+	}
+	if !p.expectPeek(token.STRING) {
+		return nil
+	}
+	exp.Path = &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
+	return exp
+}
+
+//export let xxx = <expression>;
+func (p *Parser) parseExportStatement() *ast.ExportStatement {
+	stmt := &ast.ExportStatement{
+		Token: p.curToken,
+	}
+	if !p.expectPeek(token.LET) {
+		return nil
+	}
+	stmt.Statement = p.parseLetStatement()
+	if stmt.Statement == nil {
+		return nil
+	}
+	return stmt
+}
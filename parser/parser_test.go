@@ -266,6 +266,28 @@ func TestIdentifierExpression(t *testing.T) {
 	}
 }
 
+func TestFloatLiteralExpression(t *testing.T) {
+	input := "2.5;"
+
+	l := lexer.New(input)
+	p := parser.New(l)
+
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	literal, ok := stmt.Expression.(*ast.FloatLiteral)
+	if !ok {
+		t.Fatalf("exp not *ast.FloatLiteral. got=%T", stmt.Expression)
+	}
+	if literal.Value != 2.5 {
+		t.Errorf("literal.Value not %g. got=%g", 2.5, literal.Value)
+	}
+	if literal.TokenLiteral() != "2.5" {
+		t.Errorf("literal.TokenLiteral not %s. got=%s", "2.5", literal.TokenLiteral())
+	}
+}
+
 func TestIntegerLiteralExpression(t *testing.T) {
 	// input := "f5d;"
 	// input := "5"
@@ -300,6 +322,61 @@ func TestIntegerLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestBigIntegerLiteralExpression(t *testing.T) {
+	p := parser.New(lexer.New("9223372036854775807; 9223372036854775808;"))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	small := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.IntegerLiteral)
+	if small.Big != nil || small.Value != 9223372036854775807 {
+		t.Errorf("wrong small literal. value=%d, big=%v", small.Value, small.Big)
+	}
+	big := program.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.IntegerLiteral)
+	if big.Big == nil || big.Big.String() != "9223372036854775808" {
+		t.Errorf("wrong big literal. big=%v", big.Big)
+	}
+}
+
+func TestPrefixedIntegerLiteralExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"0xFF", "255"},
+		{"0o17", "15"},
+		{"017", "15"},
+		{"0b1010", "10"},
+		{"1_000_000", "1000000"},
+		{"0x_7fff_ffff_ffff_ffff", "9223372036854775807"},
+		{"0xFFFF_FFFF_FFFF_FFFF_FF", "4722366482869645213695"},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		literal := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.IntegerLiteral)
+		value := fmt.Sprint(literal.Value)
+		if literal.Big != nil {
+			value = literal.Big.String()
+		}
+		if value != tt.expected {
+			t.Errorf("%s: wrong value. expected=%s, got=%s", tt.input, tt.expected, value)
+		}
+	}
+}
+
+func TestMalformedNumberError(t *testing.T) {
+	p := parser.New(lexer.New("let x = 0b102;"))
+	p.ParseProgram()
+
+	expected := "malformed number 0b102: invalid digit '2' in binary literal"
+	if errs := p.Errors(); len(errs) != 1 || errs[0] != expected {
+		t.Errorf("wrong parser errors: %q", errs)
+	}
+}
+
 func TestParsingPrefixExpressions(t *testing.T) {
 	prefixTests := []struct {
 		input    string
@@ -489,6 +566,46 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"add(a + b + c * d / f + g)",
 			"add((((a + b) + ((c * d) / f)) + g))",
 		},
+		{
+			"a * lib[b * c] * d",
+			"((a * (lib[(b * c)])) * d)",
+		},
+		{
+			"-lib.double(a) + lib.x",
+			"((-lib.double(a)) + lib.x)",
+		},
+		{
+			"a + b % c * d",
+			"(a + ((b % c) * d))",
+		},
+		{
+			"a ** b ** c",
+			"(a ** (b ** c))",
+		},
+		{
+			"-a ** -b * c",
+			"((-(a ** (-b))) * c)",
+		},
+		{
+			"a ** f(b)[0]",
+			"(a ** (f(b)[0]))",
+		},
+		{
+			"a | b ^ c & d == e",
+			"(a | (b ^ (c & (d == e))))",
+		},
+		{
+			"a << b + c < d >> e",
+			"((a << (b + c)) < (d >> e))",
+		},
+		{
+			"~a & ~b",
+			"((~a) & (~b))",
+		},
+		{
+			"a ?? b | c",
+			"(a ?? (b | c))",
+		},
 	}
 	for _, tt := range tests {
 		l := lexer.New(tt.input)
@@ -705,3 +822,286 @@ func TestStringLiteralExpression(t *testing.T) {
 		t.Errorf("literal.Value not %q. got=%q", "hello world", literal.Value)
 	}
 }
+
+func TestImportExpression(t *testing.T) {
+	input := `let lib = import "path/to/lib";`
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	stmt, ok := program.Statements[0].(*ast.LetStatement)
+	if !ok {
+		t.Fatalf("stmt is not ast.LetStatement. got=%T", program.Statements[0])
+	}
+	exp, ok := stmt.Value.(*ast.ImportExpression)
+	if !ok {
+		t.Fatalf("stmt.Value is not ast.ImportExpression. got=%T", stmt.Value)
+	}
+	if exp.Path.Value != "path/to/lib" {
+		t.Errorf("exp.Path.Value not %q. got=%q", "path/to/lib", exp.Path.Value)
+	}
+}
+
+func TestExportStatement(t *testing.T) {
+	input := `export let answer = 42;`
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	stmt, ok := program.Statements[0].(*ast.ExportStatement)
+	if !ok {
+		t.Fatalf("stmt is not ast.ExportStatement. got=%T", program.Statements[0])
+	}
+	if !testLetStatement(t, stmt.Statement, "answer") {
+		return
+	}
+	testLiteralExpression(t, stmt.Statement.Value, 42)
+}
+
+func TestMemberAndIndexExpressions(t *testing.T) {
+	input := `lib.name; lib["name"];`
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d\n", 2, len(program.Statements))
+	}
+
+	member, ok := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.MemberExpression)
+	if !ok {
+		t.Fatalf("exp not *ast.MemberExpression. got=%T", program.Statements[0])
+	}
+	if !testIdentifier(t, member.Object, "lib") {
+		return
+	}
+	if !testIdentifier(t, member.Property, "name") {
+		return
+	}
+
+	index, ok := program.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.IndexExpression)
+	if !ok {
+		t.Fatalf("exp not *ast.IndexExpression. got=%T", program.Statements[1])
+	}
+	if !testIdentifier(t, index.Left, "lib") {
+		return
+	}
+	if literal, ok := index.Index.(*ast.StringLiteral); !ok || literal.Value != "name" {
+		t.Errorf("index.Index not string literal %q. got=%s", "name", index.Index)
+	}
+}
+
+func TestArrayLiteral(t *testing.T) {
+	input := "[1, 2 * 2, []]"
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	array, ok := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.ArrayLiteral)
+	if !ok {
+		t.Fatalf("exp not *ast.ArrayLiteral. got=%T", program.Statements[0])
+	}
+	if len(array.Elements) != 3 {
+		t.Fatalf("len(array.Elements) not 3. got=%d", len(array.Elements))
+	}
+	testIntegerLiteral(t, array.Elements[0], 1)
+	testInfixExpression(t, array.Elements[1], 2, "*", 2)
+	if inner, ok := array.Elements[2].(*ast.ArrayLiteral); !ok || len(inner.Elements) != 0 {
+		t.Errorf("array.Elements[2] not empty array. got=%s", array.Elements[2])
+	}
+}
+
+func TestRegexLiteral(t *testing.T) {
+	p := parser.New(lexer.New(`/a\/b+/im.match(s)`))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	call := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.CallExpression)
+	re, ok := call.Function.(*ast.MemberExpression).Object.(*ast.RegexLiteral)
+	if !ok {
+		t.Fatalf("exp not *ast.RegexLiteral. got=%T", call.Function.(*ast.MemberExpression).Object)
+	}
+	if re.Pattern != `a\/b+` || re.Flags != "im" {
+		t.Errorf("wrong regex. pattern=%q, flags=%q", re.Pattern, re.Flags)
+	}
+}
+
+func TestHashLiteral(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{}`, "{}"},
+		{`{"one": 1, 2: 0 + 1, true: [x]}`, "{one: 1, 2: (0 + 1), true: [x]}"},
+		{`{"a": {"b": f(1)}}["a"]`, "({a: {b: f(1)}}[a])"},
+	}
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		if got := program.String(); got != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, got)
+		}
+	}
+
+	for _, input := range []string{`{"a" 1}`, `{"a": 1 "b": 2}`, `{"a": 1,}`} {
+		p := parser.New(lexer.New(input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("no parser errors for %q", input)
+		}
+	}
+}
+
+func TestNullAndOptionalExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`null`, "null"},
+		{`a ?? b ?? c`, "((a ?? b) ?? c)"},
+		{`a == null ?? b < c`, "((a == null) ?? (b < c))"},
+		{`a ?? b + 1`, "(a ?? (b + 1))"},
+		{`f?.(1, x)`, "f?.(1, x)"},
+		{`h?.["k"]?.y ?? 0`, "((h?.[k])?.y ?? 0)"},
+		{`s?.[1:]`, "(s?.[1:])"},
+		{`-m?.f(2)`, "(-m?.f(2))"},
+	}
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		if got := program.String(); got != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, got)
+		}
+	}
+
+	p := parser.New(lexer.New(`a?.1`))
+	p.ParseProgram()
+	if errs := p.Errors(); len(errs) == 0 || errs[0] != "expected (, [ or a name after ?., got INT" {
+		t.Errorf("wrong parser errors: %q", errs)
+	}
+}
+
+func TestConditionalExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`a ? b : c`, "(a ? b : c)"},
+		{`a ? b : c ? d : e`, "(a ? b : (c ? d : e))"},
+		{`a ? b ? c : d : e`, "(a ? (b ? c : d) : e)"},
+		{`x < 1 ?? y ? a + 1 : b ?? c`, "(((x < 1) ?? y) ? (a + 1) : (b ?? c))"},
+		{`f(a ? 1 : 2, {"k": b ? 3 : 4})`, "f((a ? 1 : 2), {k: (b ? 3 : 4)})"},
+		{`s[a ? 1 : 2]`, "(s[(a ? 1 : 2)])"},
+		{`r?.match(s) ? 1 : 0`, "(r?.match(s) ? 1 : 0)"},
+	}
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		if got := program.String(); got != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, got)
+		}
+	}
+
+	for _, input := range []string{`a ? b`, `a ? b c`, `a ? : c`} {
+		p := parser.New(lexer.New(input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("no parser errors for %q", input)
+		}
+	}
+}
+
+func TestMatchExpression(t *testing.T) {
+	input := `match (x + 1) { 1 | -2 | "a" => one, 2.5 if y > 1 => f(y), null | true => n, _ => {"k": 0}, }`
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	exp, ok := stmt.Expression.(*ast.MatchExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.MatchExpression. got=%T", stmt.Expression)
+	}
+	if !testInfixExpression(t, exp.Value, "x", "+", 1) {
+		return
+	}
+	if len(exp.Arms) != 4 {
+		t.Fatalf("wrong number of arms. got=%d", len(exp.Arms))
+	}
+	expected := []string{`1 | (-2) | a => one`, `2.5 if (y > 1) => f(y)`, `null | true => n`, `_ => {k: 0}`}
+	for i, arm := range exp.Arms {
+		if arm.String() != expected[i] {
+			t.Errorf("arms[%d] wrong. expected=%q, got=%q", i, expected[i], arm.String())
+		}
+	}
+	if len(exp.Arms[3].Patterns) != 0 || exp.Arms[3].Token.Literal != "_" {
+		t.Errorf("default arm has patterns %v", exp.Arms[3].Patterns)
+	}
+
+	p = parser.New(lexer.New(`match (x) {}`))
+	program = p.ParseProgram()
+	checkParserErrors(t, p)
+	if got := program.String(); got != "match (x) {}" {
+		t.Errorf("wrong empty match. got=%q", got)
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`match (x) { y => 1 }`, "expected a literal pattern, got y"},
+		{`match (x) { 1 + 2 => 1 }`, "expected a literal pattern, got (1 + 2)"},
+		{`match (x) { 1 | _ => 1 }`, "expected a literal pattern, got _"},
+		{`match (x) { !true => 1 }`, "expected a literal pattern, got (!true)"},
+		{`match (x) { 1 2 }`, "expected next token to be =>, but got INT"},
+	}
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		p.ParseProgram()
+		if errs := p.Errors(); len(errs) == 0 || errs[0] != tt.expected {
+			t.Errorf("%s: wrong parser errors. expected=%q, got=%q", tt.input, tt.expected, errs)
+		}
+	}
+}
+
+func TestSliceExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a[1:2]", "(a[1:2])"},
+		{"a[:n - 1]", "(a[:(n - 1)])"},
+		{"a[i:]", "(a[i:])"},
+		{"a[:]", "(a[:])"},
+		{"f(x)[1:][0]", "((f(x)[1:])[0])"},
+	}
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		if _, ok := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.SliceExpression); !ok && tt.input != "f(x)[1:][0]" {
+			t.Errorf("exp not *ast.SliceExpression. got=%T", program.Statements[0].(*ast.ExpressionStatement).Expression)
+		}
+		if got := program.String(); got != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, got)
+		}
+	}
+}
+
+func TestOptionalSemicolons(t *testing.T) {
+	input := `let x = 5
+return x
+let y = 10`
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	if len(program.Statements) != 3 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d\n", 3, len(program.Statements))
+	}
+	testLetStatement(t, program.Statements[0], "x")
+	testLetStatement(t, program.Statements[2], "y")
+}
@@ -0,0 +1,116 @@
+package repl
+
+import (
+	"interpreter/evaluator"
+	"interpreter/lexer"
+	"interpreter/token"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ANSI escape sequences used to style the REPL.
+const (
+	RESET   = "\x1b[0m"
+	BOLD    = "\x1b[1m"
+	DIM     = "\x1b[2m"
+	RED     = "\x1b[31m"
+	GREEN   = "\x1b[32m"
+	YELLOW  = "\x1b[33m"
+	BLUE    = "\x1b[34m"
+	MAGENTA = "\x1b[35m"
+	CYAN    = "\x1b[36m"
+)
+
+// ColorEnabled reports whether output to out should be colored: out
+// must be a terminal and the NO_COLOR environment variable unset or
+// empty.
+func ColorEnabled(out io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	f, ok := out.(*os.File)
+	return ok && isTerminal(f)
+}
+
+// paint wraps s in style when color is on.
+func paint(color bool, style, s string) string {
+	if !color || s == "" {
+		return s
+	}
+	return style + s + RESET
+}
+
+// tokenStyle returns the style of a token, "" for plain text.
+func tokenStyle(tok token.Token) string {
+	switch tok.Type {
+	case token.INT, token.FLOAT:
+		return CYAN
+	case token.STRING, token.REGEX:
+		return GREEN
+	case token.TRUE, token.FALSE, token.NULL:
+		return YELLOW
+	case token.COMMENT:
+		return DIM
+	case token.IDENT:
+		if _, ok := evaluator.GetBuiltin(tok.Literal); ok {
+			return BLUE
+		}
+		return ""
+	case token.ILLEGAL:
+		return RED
+	}
+	if token.CheckIdent(tok.Literal) == tok.Type {
+		return MAGENTA + BOLD
+	}
+	return ""
+}
+
+// Highlight colors the tokens of src. Text the lexer skips, such as
+// white space, is copied unchanged.
+func Highlight(src string) string {
+	//offset of the start of each line, to turn token positions into
+	//offsets into src
+	lines := []int{0}
+	for i := 0; i < len(src); i++ {
+		if src[i] == '\n' {
+			lines = append(lines, i+1)
+		}
+	}
+	l := lexer.New(src)
+	var tokens []token.Token
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		tokens = append(tokens, tok)
+	}
+	tokens = append(tokens, l.Comments()...)
+	sort.Slice(tokens, func(i, j int) bool {
+		a, b := tokens[i], tokens[j]
+		return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+	})
+
+	var b strings.Builder
+	pos := 0
+	for _, tok := range tokens {
+		start := lines[tok.Line-1] + tok.Column - 1
+		end := start + len(tok.Literal)
+		if tok.Type == token.STRING {
+			end += 2
+		}
+		if start < pos {
+			continue
+		}
+		if end > len(src) {
+			end = len(src)
+		}
+		b.WriteString(src[pos:start])
+		if style := tokenStyle(tok); style != "" {
+			b.WriteString(style + src[start:end] + RESET)
+		} else {
+			b.WriteString(src[start:end])
+		}
+		pos = end
+	}
+	b.WriteString(src[pos:])
+	return b.String()
+}
@@ -0,0 +1,440 @@
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// MAX_HISTORY is the number of history entries kept in the history file.
+const MAX_HISTORY = 1000
+
+// errInterrupt is returned by ReadLine when the user presses Ctrl-C.
+var errInterrupt = errors.New("interrupt")
+
+// lineReader reads the input of the REPL one line at a time.
+type lineReader interface {
+	ReadLine(prompt string) (string, error)
+}
+
+// scanReader reads lines from piped input, without editing.
+type scanReader struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+}
+
+func (r *scanReader) ReadLine(prompt string) (string, error) {
+	io.WriteString(r.out, prompt)
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return r.scanner.Text(), nil
+}
+
+const (
+	keyCtrlA     = 1
+	keyCtrlB     = 2
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyCtrlF     = 6
+	keyCtrlG     = 7
+	keyCtrlH     = 8
+	keyTab       = 9
+	keyLF        = 10
+	keyCtrlK     = 11
+	keyCtrlL     = 12
+	keyEnter     = 13
+	keyCtrlN     = 14
+	keyCtrlP     = 16
+	keyCtrlR     = 18
+	keyCtrlU     = 21
+	keyCtrlW     = 23
+	keyEscape    = 27
+	keyBackspace = 127
+
+	//escape sequences are decoded to keys past the unicode range
+	keyUp = unicode.MaxRune + 1 + iota
+	keyDown
+	keyRight
+	keyLeft
+	keyHome
+	keyEnd
+	keyDelete
+	keyUnknown
+)
+
+// editor is a line editor for terminals: it moves the cursor, recalls
+// and searches history and completes names.
+type editor struct {
+	in  *bufio.Reader
+	out io.Writer
+
+	history     []string
+	historyFile string //entries are appended to it, "" to keep them in memory
+
+	//complete returns the candidates starting with prefix
+	complete func(prefix string) []string
+	//highlight, if set, styles the line as it is drawn
+	highlight func(line string) string
+
+	line   []rune
+	pos    int
+	prompt string
+}
+
+func newEditor(in io.Reader, out io.Writer, complete func(string) []string) *editor {
+	return &editor{in: bufio.NewReader(in), out: out, complete: complete}
+}
+
+// HistoryFile returns the path of the REPL history file under the user
+// configuration directory.
+func HistoryFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "interpreter", "history"), nil
+}
+
+// loadHistory reads the history file, keeping its last MAX_HISTORY
+// entries, and appends new entries to it.
+func (e *editor) loadHistory(path string) {
+	e.historyFile = path
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" {
+			e.history = append(e.history, line)
+		}
+	}
+	if len(e.history) > MAX_HISTORY {
+		e.history = e.history[len(e.history)-MAX_HISTORY:]
+		os.WriteFile(path, []byte(strings.Join(e.history, "\n")+"\n"), 0600)
+	}
+}
+
+func (e *editor) addHistory(line string) {
+	if strings.TrimSpace(line) == "" || (len(e.history) > 0 && e.history[len(e.history)-1] == line) {
+		return
+	}
+	e.history = append(e.history, line)
+	if e.historyFile == "" {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(e.historyFile), 0700); err != nil {
+		return
+	}
+	f, err := os.OpenFile(e.historyFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	fmt.Fprintln(f, line)
+	f.Close()
+}
+
+// readKey reads a rune, decoding the escape sequences of special keys.
+func (e *editor) readKey() (rune, error) {
+	r, _, err := e.in.ReadRune()
+	if err != nil || r != keyEscape {
+		return r, err
+	}
+	if e.in.Buffered() == 0 {
+		return keyEscape, nil
+	}
+	b, _ := e.in.ReadByte()
+	if b != '[' && b != 'O' {
+		return keyUnknown, nil
+	}
+	b, _ = e.in.ReadByte()
+	switch b {
+	case 'A':
+		return keyUp, nil
+	case 'B':
+		return keyDown, nil
+	case 'C':
+		return keyRight, nil
+	case 'D':
+		return keyLeft, nil
+	case 'H':
+		return keyHome, nil
+	case 'F':
+		return keyEnd, nil
+	}
+	//sequences like ESC [ 3 ~, possibly with parameters
+	seq := []byte{b}
+	for b >= '0' && b <= '9' || b == ';' {
+		if b, err = e.in.ReadByte(); err != nil {
+			return keyUnknown, nil
+		}
+		seq = append(seq, b)
+	}
+	switch string(seq) {
+	case "1~", "7~":
+		return keyHome, nil
+	case "4~", "8~":
+		return keyEnd, nil
+	case "3~":
+		return keyDelete, nil
+	}
+	return keyUnknown, nil
+}
+
+// ReadLine reads a line, letting the user edit it. The terminal must be
+// in raw mode.
+func (e *editor) ReadLine(prompt string) (string, error) {
+	e.prompt, e.line, e.pos = prompt, nil, 0
+	//index into history while browsing it, len(history) for the new line
+	index := len(e.history)
+	pending := ""
+	e.refresh()
+	for {
+		key, err := e.readKey()
+		if err != nil {
+			if err == io.EOF && len(e.line) > 0 {
+				break
+			}
+			return "", err
+		}
+		switch key {
+		case keyEnter, keyLF:
+			io.WriteString(e.out, "\r\n")
+			line := string(e.line)
+			e.addHistory(line)
+			return line, nil
+		case keyCtrlC:
+			io.WriteString(e.out, "^C\r\n")
+			return "", errInterrupt
+		case keyCtrlD:
+			if len(e.line) == 0 {
+				io.WriteString(e.out, "\r\n")
+				return "", io.EOF
+			}
+			e.deleteAt(e.pos)
+		case keyBackspace, keyCtrlH:
+			if e.pos > 0 {
+				e.pos--
+				e.deleteAt(e.pos)
+			}
+		case keyDelete:
+			e.deleteAt(e.pos)
+		case keyLeft, keyCtrlB:
+			if e.pos > 0 {
+				e.pos--
+			}
+		case keyRight, keyCtrlF:
+			if e.pos < len(e.line) {
+				e.pos++
+			}
+		case keyHome, keyCtrlA:
+			e.pos = 0
+		case keyEnd, keyCtrlE:
+			e.pos = len(e.line)
+		case keyCtrlK:
+			e.line = e.line[:e.pos]
+		case keyCtrlU:
+			e.line = append([]rune{}, e.line[e.pos:]...)
+			e.pos = 0
+		case keyCtrlW:
+			start := e.pos
+			for start > 0 && e.line[start-1] == ' ' {
+				start--
+			}
+			for start > 0 && e.line[start-1] != ' ' {
+				start--
+			}
+			e.line = append(e.line[:start], e.line[e.pos:]...)
+			e.pos = start
+		case keyUp, keyCtrlP, keyDown, keyCtrlN:
+			if index == len(e.history) {
+				pending = string(e.line)
+			}
+			if key == keyUp || key == keyCtrlP {
+				if index == 0 {
+					continue
+				}
+				index--
+			} else {
+				if index == len(e.history) {
+					continue
+				}
+				index++
+			}
+			if index == len(e.history) {
+				e.setLine(pending)
+			} else {
+				e.setLine(e.history[index])
+			}
+		case keyCtrlR:
+			if line, done := e.search(); done {
+				io.WriteString(e.out, "\r\n")
+				e.addHistory(line)
+				return line, nil
+			}
+		case keyTab:
+			e.completeWord()
+		case keyCtrlL:
+			io.WriteString(e.out, "\x1b[H\x1b[2J")
+		default:
+			if key >= ' ' && key <= unicode.MaxRune {
+				e.insert(key)
+			}
+		}
+		e.refresh()
+	}
+	io.WriteString(e.out, "\r\n")
+	return string(e.line), nil
+}
+
+func (e *editor) setLine(s string) {
+	e.line = []rune(s)
+	e.pos = len(e.line)
+}
+
+func (e *editor) insert(r rune) {
+	e.line = append(e.line, 0)
+	copy(e.line[e.pos+1:], e.line[e.pos:])
+	e.line[e.pos] = r
+	e.pos++
+}
+
+func (e *editor) deleteAt(i int) {
+	if i < len(e.line) {
+		e.line = append(e.line[:i], e.line[i+1:]...)
+	}
+}
+
+// refresh redraws the line and puts the cursor back in place.
+func (e *editor) refresh() {
+	e.draw(e.prompt, string(e.line), e.pos)
+}
+
+func (e *editor) draw(prompt, line string, pos int) {
+	if e.highlight != nil {
+		line = e.highlight(line)
+	}
+	fmt.Fprintf(e.out, "\r%s%s\x1b[K\r", prompt, line)
+	if n := len([]rune(prompt)) + pos; n > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dC", n)
+	}
+}
+
+// search runs a reverse incremental search through history. It returns
+// the matching line and true when the user accepts it with Enter;
+// otherwise the match, if any, is left in the line for editing.
+func (e *editor) search() (string, bool) {
+	query := []rune{}
+	match := len(e.history)
+	//find looks for the query in the entries before from
+	find := func(from int) {
+		for i := from - 1; i >= 0; i-- {
+			if strings.Contains(e.history[i], string(query)) {
+				match = i
+				return
+			}
+		}
+	}
+	for {
+		line := ""
+		if match < len(e.history) {
+			line = e.history[match]
+		}
+		prompt := fmt.Sprintf("(reverse-i-search)'%s': ", string(query))
+		pos := 0
+		if i := strings.Index(line, string(query)); i > 0 {
+			pos = len([]rune(line[:i]))
+		}
+		e.draw(prompt, line, pos)
+		key, err := e.readKey()
+		if err != nil {
+			return "", false
+		}
+		switch {
+		case key == keyCtrlR:
+			find(match)
+		case key == keyBackspace || key == keyCtrlH:
+			if len(query) > 0 {
+				query = query[:len(query)-1]
+				match = len(e.history)
+				find(match)
+			}
+		case key == keyEnter || key == keyLF:
+			return line, true
+		case key == keyCtrlG || key == keyCtrlC || key == keyEscape:
+			return "", false
+		case key >= ' ' && key <= unicode.MaxRune:
+			query = append(query, key)
+			if match == len(e.history) || !strings.Contains(line, string(query)) {
+				find(match)
+			}
+		default:
+			if match < len(e.history) {
+				e.setLine(line)
+			}
+			return "", false
+		}
+	}
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == ':'
+}
+
+// completeWord completes the word before the cursor. With several
+// candidates it inserts their common prefix, or lists them when there
+// is nothing to insert.
+func (e *editor) completeWord() {
+	start := e.pos
+	for start > 0 && isWordRune(e.line[start-1]) {
+		start--
+	}
+	prefix := string(e.line[start:e.pos])
+	if prefix == "" || e.complete == nil {
+		return
+	}
+	candidates := e.complete(prefix)
+	if len(candidates) == 0 {
+		return
+	}
+	common := candidates[0]
+	for _, c := range candidates[1:] {
+		for !strings.HasPrefix(c, common) {
+			common = common[:len(common)-1]
+		}
+	}
+	if common == prefix {
+		io.WriteString(e.out, "\r\n"+strings.Join(candidates, "  ")+"\r\n")
+		return
+	}
+	for _, r := range common[len(prefix):] {
+		e.insert(r)
+	}
+}
+
+// candidates returns the sorted, distinct names of names that start
+// with prefix.
+func candidates(prefix string, names ...[]string) []string {
+	seen := map[string]bool{}
+	var found []string
+	for _, list := range names {
+		for _, name := range list {
+			if strings.HasPrefix(name, prefix) && !seen[name] {
+				seen[name] = true
+				found = append(found, name)
+			}
+		}
+	}
+	sort.Strings(found)
+	return found
+}
@@ -0,0 +1,102 @@
+package repl
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testEditor(input string, history ...string) *editor {
+	e := newEditor(strings.NewReader(input), io.Discard, func(prefix string) []string {
+		return candidates(prefix, []string{"let", "len", "length", "lengthen", "echo"})
+	})
+	e.history = history
+	return e
+}
+
+func TestEditorKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		history  []string
+		expected string
+	}{
+		{"plain", "1 + 2\r", nil, "1 + 2"},
+		{"backspace", "12\x7f3\r", nil, "13"},
+		{"left and insert", "ac\x1b[Db\r", nil, "abc"},
+		{"home and end", "bc\x01a\x05d\r", nil, "abcd"},
+		{"home and end sequences", "bc\x1b[Ha\x1b[4~d\r", nil, "abcd"},
+		{"delete", "abc\x01\x1b[3~\r", nil, "bc"},
+		{"kill to end", "abcd\x1b[D\x1b[D\x0b\r", nil, "ab"},
+		{"kill to start", "abcd\x1b[D\x15\r", nil, "d"},
+		{"delete word", "let x = foo\x17bar\r", nil, "let x = bar"},
+		{"utf-8", "héllo\x1b[D\x1b[D\x1b[D\x7f\r", nil, "hllo"},
+		{"history up", "\x1b[A\x1b[A\r", []string{"first", "second"}, "first"},
+		{"history down keeps new line", "new\x1b[A\x1b[B\r", []string{"old"}, "new"},
+		{"reverse search", "\x12ir\r", []string{"first", "second", "third"}, "third"},
+		{"reverse search again", "\x12ir\x12\r", []string{"first", "second", "third"}, "first"},
+		{"reverse search then edit", "\x12sec\x1b[C!\r", []string{"first", "second"}, "second!"},
+		{"reverse search cancel", "x\x12sec\x07\r", []string{"second"}, "x"},
+		{"complete single", "ech\t(1)\r", nil, "echo(1)"},
+		{"complete common prefix", "l\t\r", nil, "le"},
+		{"complete longer prefix", "leng\t\r", nil, "length"},
+		{"complete ambiguous", "len\t\r", nil, "len"},
+	}
+	for _, tt := range tests {
+		e := testEditor(tt.input, tt.history...)
+		line, err := e.ReadLine(">> ")
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", tt.name, err)
+			continue
+		}
+		if line != tt.expected {
+			t.Errorf("%s: wrong line. expected=%q, got=%q", tt.name, tt.expected, line)
+		}
+	}
+}
+
+func TestEditorControl(t *testing.T) {
+	e := testEditor("abc\x03\x04")
+	if _, err := e.ReadLine(">> "); err != errInterrupt {
+		t.Errorf("Ctrl-C: expected interrupt. got=%v", err)
+	}
+	if _, err := e.ReadLine(">> "); err != io.EOF {
+		t.Errorf("Ctrl-D: expected EOF. got=%v", err)
+	}
+}
+
+func TestEditorHistoryFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config", "history")
+	e := testEditor("let a = 1;\r\r:env\r\x1b[A\r")
+	e.loadHistory(path)
+	for i := 0; i < 4; i++ {
+		e.ReadLine(">> ")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "let a = 1;\n:env\n" {
+		t.Errorf("wrong history file. got=%q", data)
+	}
+
+	e = testEditor("\x1b[A\r")
+	e.loadHistory(path)
+	if line, _ := e.ReadLine(">> "); line != ":env" {
+		t.Errorf("history not loaded. got=%q", line)
+	}
+}
+
+func TestEditorListsCandidates(t *testing.T) {
+	var out bytes.Buffer
+	e := newEditor(strings.NewReader("le\t\t\r"), &out, func(prefix string) []string {
+		return candidates(prefix, []string{"len", "let"})
+	})
+	e.ReadLine(">> ")
+	if !strings.Contains(out.String(), "\r\nlen  let\r\n") {
+		t.Errorf("candidates not listed. got=%q", out.String())
+	}
+}
@@ -0,0 +1,174 @@
+package repl
+
+import (
+	"fmt"
+	"interpreter/ast"
+	"interpreter/format"
+	"interpreter/object"
+	"sort"
+	"strings"
+)
+
+// Limits of pretty-printed values; what goes past them is elided.
+const (
+	MAX_DEPTH  = 3   //nesting of values shown in full
+	MAX_ITEMS  = 20  //members of a module or elements of an array or hash
+	MAX_LINES  = 8   //lines of a function
+	MAX_STRING = 200 //characters of a string
+	INDENT     = "  "
+	LINE_WIDTH = 72 //of arrays and hashes printed on one line
+)
+
+// printer renders values for the REPL.
+type printer struct {
+	color bool
+	b     strings.Builder
+}
+
+// Pretty renders obj over several indented lines if needed, followed by
+// its type.
+func Pretty(obj object.Object, color bool) string {
+	p := &printer{color: color}
+	p.value(obj, "", 0)
+	p.b.WriteString(paint(color, DIM, "  // "+string(obj.Type())))
+	return p.b.String()
+}
+
+func (p *printer) value(obj object.Object, indent string, depth int) {
+	switch obj := obj.(type) {
+	case *object.Integer, *object.BigInteger, *object.Float:
+		p.b.WriteString(paint(p.color, CYAN, obj.Inspect()))
+	case *object.Boolean:
+		p.b.WriteString(paint(p.color, YELLOW, obj.Inspect()))
+	case *object.Null:
+		p.b.WriteString(paint(p.color, DIM, obj.Inspect()))
+	case *object.String:
+		p.b.WriteString(paint(p.color, GREEN, quote(obj.Value)))
+	case *object.Regex:
+		p.b.WriteString(paint(p.color, GREEN, obj.Inspect()))
+	case *object.Function:
+		p.function(obj, indent)
+	case *object.Module:
+		p.module(obj, indent, depth)
+	case *object.Array:
+		p.array(obj, indent, depth)
+	case *object.Hash:
+		p.hash(obj, indent, depth)
+	default:
+		p.b.WriteString(obj.Inspect())
+	}
+}
+
+// quote quotes s as a string literal, eliding its middle when it is too
+// long. Strings have no escapes, so quotes inside are left as they are.
+func quote(s string) string {
+	if runes := []rune(s); len(runes) > MAX_STRING {
+		s = string(runes[:MAX_STRING/2]) + "…" + string(runes[len(runes)-MAX_STRING/2:])
+	}
+	return `"` + s + `"`
+}
+
+// function prints the source of a function in canonical form, keeping
+// its first MAX_LINES lines.
+func (p *printer) function(fn *object.Function, indent string) {
+	src := format.Node(&ast.FunctionLiteral{Parameters: fn.Parameters, Body: fn.Body})
+	lines := strings.Split(src, "\n")
+	if len(lines) > MAX_LINES {
+		lines = append(lines[:MAX_LINES-2], "\t…", lines[len(lines)-1])
+	}
+	for i, line := range lines {
+		if i > 0 {
+			p.b.WriteString("\n" + indent)
+		}
+		line = strings.ReplaceAll(line, "\t", INDENT)
+		if p.color {
+			line = Highlight(line)
+		}
+		p.b.WriteString(line)
+	}
+}
+
+func (p *printer) module(m *object.Module, indent string, depth int) {
+	p.b.WriteString(fmt.Sprintf("module(%s) {", m.Path))
+	if len(m.Exports) == 0 {
+		p.b.WriteString("}")
+		return
+	}
+	if depth >= MAX_DEPTH {
+		p.b.WriteString("…}")
+		return
+	}
+	names := make([]string, 0, len(m.Exports))
+	for name := range m.Exports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	inner := indent + INDENT
+	for i, name := range names {
+		if i == MAX_ITEMS {
+			fmt.Fprintf(&p.b, "\n%s… %d more", inner, len(names)-MAX_ITEMS)
+			break
+		}
+		p.b.WriteString("\n" + inner + name + ": ")
+		p.value(m.Exports[name], inner, depth+1)
+	}
+	p.b.WriteString("\n" + indent + "}")
+}
+
+// array prints short arrays of one-line values on one line, and others
+// one element per line.
+func (p *printer) array(a *object.Array, indent string, depth int) {
+	p.collection("[", "]", len(a.Elements), indent, depth, func(i int, sub *printer, inner string) {
+		sub.value(a.Elements[i], inner, depth+1)
+	})
+}
+
+// hash prints hashes like arrays, one pair per element.
+func (p *printer) hash(h *object.Hash, indent string, depth int) {
+	pairs := h.Ordered()
+	p.collection("{", "}", len(pairs), indent, depth, func(i int, sub *printer, inner string) {
+		sub.value(pairs[i].Key, inner, depth+1)
+		sub.b.WriteString(": ")
+		sub.value(pairs[i].Value, inner, depth+1)
+	})
+}
+
+// collection prints the first MAX_ITEMS of size elements between open
+// and close, on one line if they fit in LINE_WIDTH.
+func (p *printer) collection(open, close string, size int, indent string, depth int, element func(i int, sub *printer, inner string)) {
+	if size == 0 {
+		p.b.WriteString(open + close)
+		return
+	}
+	if depth >= MAX_DEPTH {
+		p.b.WriteString(open + "…" + close)
+		return
+	}
+	n := size
+	if n > MAX_ITEMS {
+		n = MAX_ITEMS
+	}
+	inner := indent + INDENT
+	elements := make([]string, n)
+	width := 0
+	for i := range elements {
+		sub := &printer{color: p.color}
+		element(i, sub, inner)
+		elements[i] = sub.b.String()
+		plain := &printer{}
+		element(i, plain, inner)
+		width += len([]rune(plain.b.String())) + 2
+	}
+	if n == size && width <= LINE_WIDTH && !strings.Contains(strings.Join(elements, ""), "\n") {
+		p.b.WriteString(open + strings.Join(elements, ", ") + close)
+		return
+	}
+	p.b.WriteString(open)
+	for _, el := range elements {
+		p.b.WriteString("\n" + inner + el + ",")
+	}
+	if n < size {
+		fmt.Fprintf(&p.b, "\n%s… %d more", inner, size-n)
+	}
+	p.b.WriteString("\n" + indent + close)
+}
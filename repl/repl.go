@@ -3,46 +3,388 @@ package repl
 import (
 	"bufio"
 	"fmt"
+	"interpreter/ast"
+	"interpreter/debugger"
 	"interpreter/evaluator"
+	"interpreter/format"
 	"interpreter/lexer"
 	"interpreter/object"
 	"interpreter/parser"
+	"interpreter/token"
 	"io"
+	"os"
+	"path/filepath"
+	"strings"
 )
 
 const PROMPT = ">> "
 
-func Start(in io.Reader, out io.Writer) {
-	scanner := bufio.NewScanner(in)
-	env := object.NewEnvironment()
-	for {
-		fmt.Print(PROMPT)
+// CONTINUATION_PROMPT is shown while the input so far is incomplete.
+const CONTINUATION_PROMPT = ".. "
+
+const HELP = `Enter expressions and statements to evaluate them. Input continues on
+the next line while braces, parentheses or a string are left open.
+
+:help         show this help
+:env          list the bindings of the session
+:reset        forget all bindings
+:load file    evaluate a file in the session
+:save file    write the input evaluated so far to a file
+:ast expr     show the syntax tree of expr
+:tokens expr  show the tokens of expr
+`
+
+// COMMANDS lists the meta-commands, for completion.
+var COMMANDS = []string{":help", ":env", ":reset", ":load", ":save", ":ast", ":tokens"}
+
+// Options configure the REPL.
+type Options struct {
+	Color   bool                   //highlight input and output with ANSI escapes
+	Runtime func() *object.Runtime //creates the runtime of the session, object.NewRuntime if nil
+}
 
-		scanned := scanner.Scan()
-		if !scanned {
+// session is the state of a REPL between two inputs.
+type session struct {
+	out     io.Writer
+	color   bool
+	runtime func() *object.Runtime
+	env     *object.Environment
+	history []string //inputs evaluated without errors, for :save
+}
+
+func newSession(out io.Writer, opts Options) *session {
+	s := &session{out: out, color: opts.Color, runtime: opts.Runtime}
+	if s.runtime == nil {
+		s.runtime = object.NewRuntime
+	}
+	s.reset()
+	return s
+}
+
+func (s *session) reset() {
+	rt := s.runtime()
+	rt.Stdout = s.out
+	s.env = object.NewModuleEnvironment(rt, "")
+	s.history = nil
+}
+
+// Start runs the REPL. When in is a terminal, lines are read with a
+// line editor; otherwise they are read as they come.
+func Start(in io.Reader, out io.Writer, opts Options) {
+	s := newSession(out, opts)
+	var r lineReader = &scanReader{scanner: bufio.NewScanner(in), out: out}
+	if f, ok := in.(*os.File); ok && isTerminal(f) {
+		e := newEditor(f, out, s.complete)
+		if opts.Color {
+			e.highlight = Highlight
+		}
+		if path, err := HistoryFile(); err == nil {
+			e.loadHistory(path)
+		}
+		r = &rawReader{f: f, editor: e}
+	}
+	for {
+		input, err := readInput(r)
+		if err == errInterrupt {
+			continue
+		}
+		if err != nil {
 			return
 		}
-		line := scanner.Text()
-		l := lexer.New(line)
-		p := parser.New(l)
+		if strings.HasPrefix(strings.TrimSpace(input), ":") {
+			s.command(strings.TrimSpace(input))
+			continue
+		}
+		s.eval(input, s.env)
+	}
+}
 
-		program := p.ParseProgram()
+// readInput reads lines until they form a complete input.
+func readInput(r lineReader) (string, error) {
+	var lines []string
+	prompt := PROMPT
+	for {
+		line, err := r.ReadLine(prompt)
+		if err == io.EOF && len(lines) > 0 {
+			//let the parser report what is missing
+			return strings.Join(lines, "\n"), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, line)
+		input := strings.Join(lines, "\n")
+		if !Incomplete(input) || strings.HasPrefix(strings.TrimSpace(input), ":") {
+			return input, nil
+		}
+		prompt = CONTINUATION_PROMPT
+	}
+}
 
-		if len(p.Errors()) != 0 {
-			printParserErrors(out, p.Errors())
-			continue
+// rawReader puts the terminal in raw mode while the editor reads a line.
+type rawReader struct {
+	f      *os.File
+	editor *editor
+}
+
+func (r *rawReader) ReadLine(prompt string) (string, error) {
+	restore, err := makeRaw(r.f)
+	if err != nil {
+		return "", err
+	}
+	defer restore()
+	return r.editor.ReadLine(prompt)
+}
+
+// complete returns the keywords, builtins, bindings and commands
+// starting with prefix.
+func (s *session) complete(prefix string) []string {
+	return candidates(prefix, token.Keywords(), evaluator.BuiltinNames(), s.env.Names(), COMMANDS)
+}
+
+// Incomplete reports whether src leaves a brace, parenthesis, bracket
+// or string open, so that more lines are needed to parse it.
+func Incomplete(src string) bool {
+	depth := 0
+	l := lexer.New(src)
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		switch tok.Type {
+		case token.LPAREN, token.LBRACE, token.LBRACKET:
+			depth++
+		case token.RPAREN, token.RBRACE, token.RBRACKET:
+			depth--
+		case token.STRING:
+			// an unterminated string runs to the end of src
+			if offset(src, tok)+1+len(tok.Literal) == len(src) {
+				return true
+			}
 		}
+	}
+	return depth > 0
+}
+
+// offset returns the offset into src of the first character of tok.
+func offset(src string, tok token.Token) int {
+	i := 0
+	for line := 1; line < tok.Line; line++ {
+		i += strings.IndexByte(src[i:], '\n') + 1
+	}
+	return i + tok.Column - 1
+}
+
+// eval evaluates input in env, which is the session environment except
+// for loaded files, and prints its value.
+func (s *session) eval(input string, env *object.Environment) {
+	if strings.TrimSpace(input) == "" {
+		return
+	}
+	l := lexer.New(input)
+	p := parser.New(l)
+
+	program := p.ParseProgram()
 
-		evaluated := evaluator.Eval(program, env)
-		if evaluated != nil {
-			io.WriteString(out, evaluated.Inspect())
-			io.WriteString(out, "\n")
+	if len(p.Errors()) != 0 {
+		s.printParserErrors(input, p.ErrorList())
+		return
+	}
+
+	evaluated := evaluator.Eval(program, env)
+	if err, ok := evaluated.(*object.Error); ok {
+		s.printError(input, program, err)
+		return
+	}
+	if evaluated != nil {
+		io.WriteString(s.out, Pretty(evaluated, s.color))
+		io.WriteString(s.out, "\n")
+	}
+	s.history = append(s.history, input)
+}
+
+func (s *session) command(line string) {
+	name, arg := line, ""
+	if i := strings.IndexAny(line, " \t"); i >= 0 {
+		name, arg = line[:i], strings.TrimSpace(line[i+1:])
+	}
+	switch name {
+	case ":help":
+		io.WriteString(s.out, HELP)
+	case ":env":
+		for _, name := range s.env.Names() {
+			obj, _ := s.env.Get(name)
+			fmt.Fprintf(s.out, "%s = %s\n", name, debugger.Summary(obj))
+		}
+	case ":reset":
+		s.reset()
+	case ":load":
+		s.load(arg)
+	case ":save":
+		s.save(arg)
+	case ":ast":
+		s.printAST(arg)
+	case ":tokens":
+		s.printTokens(arg)
+	default:
+		fmt.Fprintf(s.out, "unknown command %s, type :help for the list\n", name)
+	}
+}
+
+func (s *session) load(path string) {
+	if path == "" {
+		io.WriteString(s.out, "usage: :load file\n")
+		return
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		fmt.Fprintln(s.out, err)
+		return
+	}
+	src, err := os.ReadFile(abs)
+	if err != nil {
+		fmt.Fprintln(s.out, err)
+		return
+	}
+	//the file is evaluated in an environment of its own so that its
+	//functions keep resolving imports relative to it
+	env := object.NewFileEnvironment(s.env, abs)
+	s.eval(string(src), env)
+	for _, name := range env.Names() {
+		obj, _ := env.Get(name)
+		s.env.Set(name, obj)
+	}
+}
+
+func (s *session) save(path string) {
+	if path == "" {
+		io.WriteString(s.out, "usage: :save file\n")
+		return
+	}
+	var b strings.Builder
+	for _, input := range s.history {
+		b.WriteString(input)
+		b.WriteString("\n")
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		fmt.Fprintln(s.out, err)
+		return
+	}
+	fmt.Fprintf(s.out, "saved %d inputs to %s\n", len(s.history), path)
+}
+
+// printAST prints one node per line, indented by depth, with the
+// token of the node.
+func (s *session) printAST(src string) {
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		s.printParserErrors(src, p.ErrorList())
+		return
+	}
+	depth := 0
+	ast.Inspect(program, func(node ast.Node) bool {
+		if node == nil {
+			depth--
+			return false
+		}
+		name := strings.TrimPrefix(fmt.Sprintf("%T", node), "*ast.")
+		if _, ok := node.(*ast.Program); ok {
+			fmt.Fprintf(s.out, "%s\n", name)
+		} else {
+			fmt.Fprintf(s.out, "%s%s %q\n", strings.Repeat("  ", depth), name, node.TokenLiteral())
+		}
+		depth++
+		return true
+	})
+}
+
+func (s *session) printTokens(src string) {
+	l := lexer.New(src)
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		fmt.Fprintf(s.out, "%d:%d\t%s\t%q\n", tok.Line, tok.Column, tok.Type, tok.Literal)
+	}
+}
+
+func (s *session) printParserErrors(src string, errors []parser.Error) {
+	for _, err := range errors {
+		io.WriteString(s.out, "\t"+paint(s.color, RED, err.Message)+"\n")
+		s.underline(src, err.Token, err.Token)
+	}
+}
+
+// printError prints a runtime error. Errors raised in program, parsed
+// from src, are shown with the offending source underlined; errors
+// raised in a file with their position and those raised in code from
+// an earlier input with the code.
+func (s *session) printError(src string, program *ast.Program, err *object.Error) {
+	io.WriteString(s.out, paint(s.color, RED+BOLD, err.Inspect())+"\n")
+	if err.Node == nil {
+		return
+	}
+	first, last := ast.Span(err.Node)
+	switch {
+	case first.Line == 0:
+	case err.File != "":
+		fmt.Fprintf(s.out, "\tat %s:%d:%d\n", err.File, first.Line, first.Column)
+	case contains(program, err.Node):
+		s.underline(src, first, last)
+	default:
+		code := format.Node(err.Node)
+		if s.color {
+			code = Highlight(code)
 		}
+		io.WriteString(s.out, "\tat "+code+"\n")
 	}
 }
 
-func printParserErrors(out io.Writer, errors []string) {
-	for _, msg := range errors {
-		io.WriteString(out, "\t"+msg+"\n")
+func contains(root, node ast.Node) bool {
+	found := false
+	ast.Inspect(root, func(n ast.Node) bool {
+		found = found || n == node
+		return !found
+	})
+	return found
+}
+
+// underline prints the line of src first is on and marks the tokens
+// from first to last under it, up to the end of the line.
+func (s *session) underline(src string, first, last token.Token) {
+	lines := strings.Split(src, "\n")
+	if first.Line < 1 || first.Line > len(lines) || first.Column < 1 {
+		return
+	}
+	line := lines[first.Line-1]
+	start := first.Column - 1
+	end := len(line)
+	if last.Line == first.Line {
+		end = last.Column - 1 + len(last.Literal)
+		if last.Type == token.STRING {
+			end += 2
+		}
+	}
+	if start > len(line) {
+		start = len(line)
+	}
+	if end > len(line) {
+		end = len(line)
+	}
+	width := len([]rune(line[start:end]))
+	if width == 0 {
+		//a missing token at the end of the line
+		width = 1
+	}
+	//keep tabs so that the marks line up with the source
+	var marks strings.Builder
+	for _, c := range line[:start] {
+		if c == '\t' {
+			marks.WriteRune('\t')
+		} else {
+			marks.WriteRune(' ')
+		}
+	}
+	marks.WriteString(strings.Repeat("^", width))
+	line = strings.TrimRight(line, " ")
+	if s.color {
+		line = Highlight(line)
 	}
+	io.WriteString(s.out, "\t"+line+"\n")
+	io.WriteString(s.out, "\t"+paint(s.color, RED, marks.String())+"\n")
 }
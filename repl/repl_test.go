@@ -0,0 +1,232 @@
+package repl
+
+import (
+	"bytes"
+	"interpreter/evaluator"
+	"interpreter/lexer"
+	"interpreter/object"
+	"interpreter/parser"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIncomplete(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"1 + 2", false},
+		{"let f = fn(x) {", true},
+		{"let f = fn(x) {\n x\n};", false},
+		{"add(1,", true},
+		{"lib[", true},
+		{`"abc`, true},
+		{`"a{b"`, false},
+		{"1 } ", false},
+		{"// {", false},
+		{"let x = { // }\n", true},
+		{`/[(]/.match(s)`, false},
+		{`4 / (1 +`, true},
+		{"\"a\nb", true},
+		{"\"\"", false},
+	}
+	for _, tt := range tests {
+		if got := Incomplete(tt.input); got != tt.expected {
+			t.Errorf("Incomplete(%q) wrong. expected=%t, got=%t", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func run(input string) string {
+	var out bytes.Buffer
+	Start(strings.NewReader(input), &out, Options{})
+	return out.String()
+}
+
+func TestMultilineInput(t *testing.T) {
+	input := "let add = fn(a, b) {\n\ta + b\n};\nadd(1,\n2)\n"
+	expected := ">> .. .. >> .. 3  // INTEGER\n>> "
+	if got := run(input); got != expected {
+		t.Errorf("wrong output. expected=%q, got=%q", expected, got)
+	}
+}
+
+func TestCommands(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let b = 2; let a = fn(x) { x };\n:env\n", ">> >> a = fn(x)\nb = 2\n>> "},
+		{"let a = 1;\n:reset\na\n", ">> >> >> ERROR: identifier not found: a\n\ta\n\t^\n>> "},
+		{":ast -a\n", ">> Program\n  ExpressionStatement \"-\"\n    PrefixExpression \"-\"\n      Identifier \"a\"\n>> "},
+		{":tokens let x\n", ">> 1:1\tLET\t\"let\"\n1:5\tIDENT\t\"x\"\n>> "},
+		{":nope\n", ">> unknown command :nope, type :help for the list\n>> "},
+	}
+	for _, tt := range tests {
+		if got := run(tt.input); got != tt.expected {
+			t.Errorf("wrong output for %q. expected=%q, got=%q", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.tl")
+	run("let x = 20;\nx + true\nlet double = fn(n) {\n\tn * 2\n};\n:save " + path + "\n")
+	src, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "let x = 20;\nlet double = fn(n) {\n\tn * 2\n};\n"
+	if string(src) != expected {
+		t.Errorf("wrong saved input. expected=%q, got=%q", expected, src)
+	}
+	if got := run(":load " + path + "\ndouble(x)\n"); got != ">> >> 40  // INTEGER\n>> " {
+		t.Errorf("wrong output after load. got=%q", got)
+	}
+}
+
+func TestLoadResolvesImportsFromFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "math.tl"), []byte("export let answer = 42;\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "main.tl")
+	src := "let m = import \"./math\";\nlet answer = fn() { let later = import \"./math\"; later.answer };\n"
+	if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := run(":load " + path + "\nm.answer\nanswer()\n"); got != ">> >> 42  // INTEGER\n>> 42  // INTEGER\n>> " {
+		t.Errorf("wrong output after load. got=%q", got)
+	}
+}
+
+func TestRuntimeOption(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "answers.tl"), []byte("export let answer = 42;\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	newRuntime := func() *object.Runtime {
+		rt := object.NewRuntime()
+		rt.SearchPath = []string{dir}
+		return rt
+	}
+	var out bytes.Buffer
+	Start(strings.NewReader("let m = import \"answers\";\n:reset\nlet m = import \"answers\";\nm.answer\n"), &out, Options{Runtime: newRuntime})
+	expected := ">> >> >> >> 42  // INTEGER\n>> "
+	if out.String() != expected {
+		t.Errorf("wrong output. expected=%q, got=%q", expected, out.String())
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			"let x = 1;\nlet y = x + true;\n",
+			"ERROR: type mismatch: INTEGER + BOOLEAN\n\tlet y = x + true;\n\t        ^^^^^^^^\n",
+		},
+		{
+			"let f = fn(s) {\n\tlen(s)\n};\nf(1)\n",
+			"ERROR: argument to `len` not supported, got INTEGER\n\tat len(s)\n",
+		},
+		{
+			"len(1)\n",
+			"ERROR: argument to `len` not supported, got INTEGER\n\tlen(1)\n\t^^^^^^\n",
+		},
+		{
+			`"abc" - "d"` + "\n",
+			"ERROR: unknown operator: STRING - STRING\n\t\"abc\" - \"d\"\n\t^^^^^^^^^^^\n",
+		},
+		{
+			"let = 5;\n",
+			"\texpected next token to be IDENT, but got =\n\tlet = 5;\n\t    ^\n" +
+				"\tno prefix parse function for = found\n\tlet = 5;\n\t    ^\n",
+		},
+	}
+	for _, tt := range tests {
+		got := run(tt.input)
+		got = strings.ReplaceAll(got, ">> ", "")
+		got = strings.ReplaceAll(got, ".. ", "")
+		if got != tt.expected {
+			t.Errorf("wrong output for %q. expected=%q, got=%q", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestPretty(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"5", "5  // INTEGER"},
+		{`"a b"`, `"a b"  // STRING`},
+		{"if (false) { 1 }", "null  // NULL"},
+		{`[1, "a", [2.5, []]]`, `[1, "a", [2.5, []]]  // ARRAY`},
+		{
+			`[fn(x) { x }, "` + strings.Repeat("b", 70) + `"]`,
+			"[\n  fn(x) {\n    x;\n  },\n  \"" + strings.Repeat("b", 70) + "\",\n]  // ARRAY",
+		},
+		{"[[[[1]]]]", "[[[[…]]]]  // ARRAY"},
+		{
+			"[" + strings.Repeat("1, ", MAX_ITEMS) + "2, 3]",
+			"[\n" + strings.Repeat("  1,\n", MAX_ITEMS) + "  … 2 more\n]  // ARRAY",
+		},
+		{`{"a": 1, 2: [true], "c": {}}`, `{"a": 1, 2: [true], "c": {}}  // HASH`},
+		{
+			`{"k": "` + strings.Repeat("v", 70) + `"}`,
+			"{\n  \"k\": \"" + strings.Repeat("v", 70) + "\",\n}  // HASH",
+		},
+		{"fn(a, b) { let c = a + b; c * 2 }", "fn(a, b) {\n  let c = a + b;\n  c * 2;\n}  // FUNCTION"},
+		{
+			"fn() { 1; 2; 3; 4; 5; 6; 7; 8; 9 }",
+			"fn() {\n  1;\n  2;\n  3;\n  4;\n  5;\n  …\n}  // FUNCTION",
+		},
+	}
+	for _, tt := range tests {
+		s := newSession(nil, Options{})
+		p := parser.New(lexer.New(tt.input))
+		obj := evaluator.Eval(p.ParseProgram(), s.env)
+		if got := Pretty(obj, false); got != tt.expected {
+			t.Errorf("wrong output for %q. expected=%q, got=%q", tt.input, tt.expected, got)
+		}
+	}
+
+	long := strings.Repeat("a", MAX_STRING+10)
+	if got := quote(long); len([]rune(got)) != MAX_STRING+3 || !strings.Contains(got, "…") {
+		t.Errorf("long string not elided. got=%q", got)
+	}
+}
+
+func TestPrettyModule(t *testing.T) {
+	m := &object.Module{Path: "lib", Exports: map[string]object.Object{
+		"b":   &object.Integer{Value: 2},
+		"a":   &object.String{Value: "x"},
+		"sub": &object.Module{Path: "sub", Exports: map[string]object.Object{"c": &object.Boolean{Value: true}}},
+	}}
+	expected := "module(lib) {\n  a: \"x\"\n  b: 2\n  sub: module(sub) {\n    c: true\n  }\n}  // MODULE"
+	if got := Pretty(m, false); got != expected {
+		t.Errorf("wrong output. expected=%q, got=%q", expected, got)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	input := `let s = len("a") + 1; // done`
+	expected := MAGENTA + BOLD + "let" + RESET + " s = " + BLUE + "len" + RESET + "(" + GREEN + `"a"` + RESET +
+		") + " + CYAN + "1" + RESET + "; " + DIM + "// done" + RESET
+	if got := Highlight(input); got != expected {
+		t.Errorf("wrong highlighting. expected=%q, got=%q", expected, got)
+	}
+	if got := Highlight(`echo("open`); got != BLUE+"echo"+RESET+"("+GREEN+`"open`+RESET {
+		t.Errorf("wrong highlighting of unterminated string. got=%q", got)
+	}
+
+	var out bytes.Buffer
+	Start(strings.NewReader("1\n"), &out, Options{Color: true})
+	if !strings.Contains(out.String(), CYAN+"1"+RESET+DIM+"  // INTEGER"+RESET) {
+		t.Errorf("result not colored. got=%q", out.String())
+	}
+}
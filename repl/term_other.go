@@ -0,0 +1,19 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package repl
+
+import (
+	"errors"
+	"os"
+)
+
+//the line editor is only available on linux and darwin, elsewhere the
+//REPL reads plain lines
+func isTerminal(f *os.File) bool {
+	return false
+}
+
+func makeRaw(f *os.File) (func(), error) {
+	return nil, errors.New("raw terminal mode not supported")
+}
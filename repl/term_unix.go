@@ -0,0 +1,51 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package repl
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+func getTermios(f *os.File) (*syscall.Termios, error) {
+	t := &syscall.Termios{}
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), ioctlGetTermios, uintptr(unsafe.Pointer(t)))
+	if errno != 0 {
+		return nil, errno
+	}
+	return t, nil
+}
+
+func setTermios(f *os.File, t *syscall.Termios) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), ioctlSetTermios, uintptr(unsafe.Pointer(t)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+func isTerminal(f *os.File) bool {
+	_, err := getTermios(f)
+	return err == nil
+}
+
+// makeRaw turns off line buffering, echo and signals on the terminal
+// and returns a function restoring the previous state. Output
+// processing stays on so that "\n" still starts a new line.
+func makeRaw(f *os.File) (func(), error) {
+	old, err := getTermios(f)
+	if err != nil {
+		return nil, err
+	}
+	raw := *old
+	raw.Iflag &^= syscall.ICRNL | syscall.IXON
+	raw.Lflag &^= syscall.ECHO | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if err := setTermios(f, &raw); err != nil {
+		return nil, err
+	}
+	return func() { setTermios(f, old) }, nil
+}
@@ -1,19 +1,26 @@
 package token
 
+import "sort"
+
 type TokenType string
 
 type Token struct {
 	Type    TokenType
 	Literal string
+	Line    int //1-based line of the first character
+	Column  int //1-based byte offset of the first character in its line
 }
 
 const (
 	ILLEGAL = "ILLEGAL"
 	EOF     = "EOF"
+	COMMENT = "COMMENT"
 
 	IDENT  = "IDENT"
 	INT    = "INT"
+	FLOAT  = "FLOAT"
 	STRING = "STRING"
+	REGEX  = "REGEX" // /pattern/flags
 
 	ASSIGN = "="
 
@@ -22,6 +29,15 @@ const (
 	MINUS    = "-"
 	ASTERISK = "*"
 	SLASH    = "/"
+	PERCENT  = "%"
+	POWER    = "**"
+
+	AMPERSAND = "&"
+	PIPE      = "|"
+	CARET     = "^"
+	SHL       = "<<"
+	SHR       = ">>"
+	TILDE     = "~"
 
 	BANG = "!"
 
@@ -31,21 +47,34 @@ const (
 	EQ     = "=="
 	NOT_EQ = "!="
 
+	NULLISH      = "??"
+	QUESTION_DOT = "?."
+	QUESTION     = "?"
+	ARROW        = "=>"
+
 	COMMA     = ","
 	SEMICOLON = ";"
+	COLON     = ":"
+	DOT       = "."
 
-	LPAREN = "("
-	RPAREN = ")"
-	LBRACE = "{"
-	RBRACE = "}"
+	LPAREN   = "("
+	RPAREN   = ")"
+	LBRACE   = "{"
+	RBRACE   = "}"
+	LBRACKET = "["
+	RBRACKET = "]"
 
 	FUNCTION = "FUNCTION"
 	LET      = "LET"
 	TRUE     = "TRUE"
 	FALSE    = "FALSE"
+	NULL     = "NULL"
 	IF       = "IF"
 	ELSE     = "ELSE"
 	RETURN   = "RETURN"
+	IMPORT   = "IMPORT"
+	EXPORT   = "EXPORT"
+	MATCH    = "MATCH"
 )
 
 var keywords = map[string]TokenType{
@@ -53,9 +82,23 @@ var keywords = map[string]TokenType{
 	"let":    LET,
 	"true":   TRUE,
 	"false":  FALSE,
+	"null":   NULL,
 	"if":     IF,
 	"else":   ELSE,
 	"return": RETURN,
+	"import": IMPORT,
+	"export": EXPORT,
+	"match":  MATCH,
+}
+
+//Keywords returns the reserved words of the language in sorted order.
+func Keywords() []string {
+	words := make([]string, 0, len(keywords))
+	for word := range keywords {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	return words
 }
 
 //check ident is whether in keywords.